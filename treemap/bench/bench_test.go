@@ -25,8 +25,10 @@ package bench
 
 import (
 	"cmp"
+	"fmt"
 	"math/rand/v2"
 	"runtime"
+	"slices"
 	"testing"
 
 	gods "github.com/emirpasic/gods/v2/maps/treemap"
@@ -64,6 +66,53 @@ func BenchmarkInsertRand(b *testing.B) {
 	}
 }
 
+func BenchmarkInsertSeq(b *testing.B) {
+	for b.Loop() {
+		m := treemap.New[int, int]()
+
+		for k := range N {
+			m.Insert(k, k)
+		}
+	}
+}
+
+func BenchmarkInsertSeqLeafCache(b *testing.B) {
+	for b.Loop() {
+		m := treemap.New[int, int]()
+		m.UseLeafCache()
+
+		for k := range N {
+			m.Insert(k, k)
+		}
+	}
+}
+
+func BenchmarkAppendSeq(b *testing.B) {
+	for b.Loop() {
+		m := treemap.New[int, int]()
+
+		for k := range N {
+			m.Append(k, k)
+		}
+	}
+}
+
+func BenchmarkBulkAppendSeq(b *testing.B) {
+	keys := make([]int, N)
+
+	for i := range keys {
+		keys[i] = i
+	}
+
+	for b.Loop() {
+		m := treemap.New[int, int]()
+
+		if err := m.BulkAppend(keys, keys); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func BenchmarkLookupRand(b *testing.B) {
 	m := treemap.New[int, int]()
 
@@ -137,6 +186,66 @@ func BenchmarkRemoveRand(b *testing.B) {
 	}
 }
 
+func benchmarkInsertRemoveRand(b *testing.B, m *treemap.Map[int, int]) {
+	b.Helper()
+
+	for b.Loop() {
+		for _, k := range a {
+			m.Insert(k, k)
+		}
+
+		for _, k := range d {
+			m.Remove(k)
+		}
+	}
+}
+
+func BenchmarkInsertRemoveRand(b *testing.B) {
+	benchmarkInsertRemoveRand(b, treemap.New[int, int]())
+}
+
+func BenchmarkInsertRemoveRandNodePool(b *testing.B) {
+	m := treemap.New[int, int]()
+	m.UseNodePool()
+
+	benchmarkInsertRemoveRand(b, m)
+}
+
+func benchmarkRemoveInsertRand(b *testing.B, m *treemap.Map[int, int]) {
+	b.Helper()
+
+	for _, k := range a {
+		m.Insert(k, k)
+	}
+
+	for b.Loop() {
+		for _, k := range d {
+			m.Remove(k)
+			m.Insert(k, k)
+			m.Remove(k + N)
+			m.Insert(k+N, k)
+		}
+	}
+}
+
+func BenchmarkRemoveInsertRand(b *testing.B) {
+	benchmarkRemoveInsertRand(b, treemap.New[int, int]())
+}
+
+func BenchmarkRemoveInsertRandLazyRebalance(b *testing.B) {
+	m := treemap.New[int, int]()
+	m.UseLazyRebalance()
+
+	benchmarkRemoveInsertRand(b, m)
+}
+
+func BenchmarkInsertRemoveRandLazyRebalance(b *testing.B) {
+	m := treemap.New[int, int]()
+	m.UseLazyRebalance()
+
+	benchmarkInsertRemoveRand(b, m)
+}
+
 func BenchmarkInsertComparableRand(b *testing.B) {
 	for b.Loop() {
 		m := treemap.NewAny[Foo, int](compareFoo)
@@ -201,6 +310,79 @@ func BenchmarkRemoveComparableRand(b *testing.B) {
 	}
 }
 
+func BenchmarkLookupRandBinarySearch(b *testing.B) {
+	m := treemap.New[int, int]()
+	m.UseBinarySearch()
+
+	for _, k := range a {
+		m.Insert(k, k)
+	}
+
+	for b.Loop() {
+		for _, k := range d {
+			m.Find(k)
+		}
+	}
+}
+
+func BenchmarkLookupRandSlicesBinarySearch(b *testing.B) {
+	m := treemap.New[int, int]()
+	m.SetSearch(slices.BinarySearch[[]int])
+
+	for _, k := range a {
+		m.Insert(k, k)
+	}
+
+	for b.Loop() {
+		for _, k := range d {
+			m.Find(k)
+		}
+	}
+}
+
+// makeStringKeys returns the string keys that share the long common
+// prefix, which makes key comparison expensive.
+func makeStringKeys(s []int) []string {
+	keys := make([]string, len(s))
+
+	for i, k := range s {
+		keys[i] = fmt.Sprintf("%0128d", k)
+	}
+
+	return keys
+}
+
+var sa, sd = makeStringKeys(a), makeStringKeys(d)
+
+func BenchmarkLookupStringRand(b *testing.B) {
+	m := treemap.New[string, int]()
+
+	for i, k := range sa {
+		m.Insert(k, i)
+	}
+
+	for b.Loop() {
+		for _, k := range sd {
+			m.Find(k)
+		}
+	}
+}
+
+func BenchmarkLookupStringRandBinarySearch(b *testing.B) {
+	m := treemap.New[string, int]()
+	m.UseBinarySearch()
+
+	for i, k := range sa {
+		m.Insert(k, i)
+	}
+
+	for b.Loop() {
+		for _, k := range sd {
+			m.Find(k)
+		}
+	}
+}
+
 func BenchmarkGODSInsertRand(b *testing.B) {
 	for b.Loop() {
 		m := gods.New[int, int]()
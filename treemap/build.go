@@ -0,0 +1,278 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"cmp"
+	"container/heap"
+	"iter"
+)
+
+// builder constructs the tree of Map bottom-up from the items given
+// in the strictly increasing order of keys.  It is much faster than
+// inserting the items one by one.
+type builder[Key, Value any] struct {
+	m      *Map[Key, Value]
+	leaves []node[Key, Value]
+	tnode  *leafNode[Key, Value]
+	n      int
+}
+
+// newBuilder returns new builder that replaces the tree of m when
+// [builder.finish] is called.  m can be used until then.
+func newBuilder[Key, Value any](m *Map[Key, Value]) *builder[Key, Value] {
+	return &builder[Key, Value]{
+		m: m,
+	}
+}
+
+// add appends the given key-value pair.  key must be greater than
+// the keys added before.
+func (b *builder[Key, Value]) add(key Key, value Value) {
+	if b.tnode == nil || b.tnode.IsFull() {
+		tnode := b.m.newLeafNode()
+
+		if b.tnode != nil {
+			b.tnode.next = tnode
+			tnode.prev = b.tnode
+		}
+
+		b.tnode = tnode
+		b.leaves = append(b.leaves, tnode)
+	}
+
+	b.tnode.keys[b.tnode.n] = key
+	b.tnode.values[b.tnode.n] = value
+	b.tnode.n++
+	b.n++
+}
+
+// finish replaces the tree of m with the one that contains the added
+// items.  The nodes of the old tree are returned to the node pool if
+// it is enabled.
+func (b *builder[Key, Value]) finish() {
+	m := b.m
+	oldRoot := m.root
+
+	if len(b.leaves) == 0 {
+		b.tnode = m.newLeafNode()
+		b.leaves = append(b.leaves, b.tnode)
+	}
+
+	// All leaf nodes except for the last one are full.  Move the
+	// items to the last one from the previous one if it has too few
+	// items.
+	if last := b.tnode; last.prev != nil && last.n < minNodes {
+		prev := last.prev
+		prev.ShiftRight(last, (prev.n+last.n+1)/2-last.n)
+	}
+
+	front := b.leaves[0].(*leafNode[Key, Value])
+	nodes := b.leaves
+	height := 0
+
+	for len(nodes) > 1 {
+		nodes = b.buildLevel(nodes)
+		height++
+	}
+
+	m.root = nodes[0]
+	m.front = front
+	m.back = b.tnode
+	m.n = b.n
+	m.height = height
+	m.cache = nil
+
+	if m.pool != nil {
+		m.freeTree(oldRoot)
+	}
+
+	*b = builder[Key, Value]{}
+}
+
+// buildLevel returns the internal nodes that have nodes as their
+// children.  The children are evenly distributed among the internal
+// nodes, so that each of them has at least minNodes children.  It
+// reuses nodes for the returned slice.
+func (b *builder[Key, Value]) buildLevel(
+	nodes []node[Key, Value],
+) []node[Key, Value] {
+	k := (len(nodes) + maxNodes - 1) / maxNodes
+	size, rem := len(nodes)/k, len(nodes)%k
+	start := 0
+
+	for i := range k {
+		n := size
+		if i < rem {
+			n++
+		}
+
+		inode := b.m.newInternalNode()
+
+		for j, node := range nodes[start : start+n] {
+			inode.nodes[j] = node
+			inode.keys[j] = node.LastKey()
+		}
+
+		inode.n = n
+		start += n
+		nodes[i] = inode
+	}
+
+	clear(nodes[k:])
+
+	return nodes[:k]
+}
+
+// runHead is the first item that is not consumed yet in a run given
+// to [CollectRuns].
+type runHead[Key, Value any] struct {
+	key   Key
+	value Value
+	// idx is the index of the run.
+	idx  int
+	next func() (Key, Value, bool)
+}
+
+// runHeap is the min-heap of runHead ordered by key, and then by the
+// index of the run.
+type runHeap[Key cmp.Ordered, Value any] []runHead[Key, Value]
+
+func (h *runHeap[Key, Value]) Len() int {
+	return len(*h)
+}
+
+func (h *runHeap[Key, Value]) Less(i, j int) bool {
+	if c := cmp.Compare((*h)[i].key, (*h)[j].key); c != 0 {
+		return c < 0
+	}
+
+	return (*h)[i].idx < (*h)[j].idx
+}
+
+func (h *runHeap[Key, Value]) Swap(i, j int) {
+	(*h)[i], (*h)[j] = (*h)[j], (*h)[i]
+}
+
+func (h *runHeap[Key, Value]) Push(x any) {
+	*h = append(*h, x.(runHead[Key, Value]))
+}
+
+func (h *runHeap[Key, Value]) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = runHead[Key, Value]{}
+	*h = old[:n-1]
+
+	return x
+}
+
+// CollectRuns merges runs, each of which yields the keys in the
+// increasing order, and returns a new Map created by [New] that
+// contains all the yielded items.  If a key is yielded more than
+// once, the value yielded last wins, where the runs are ordered by
+// their positions in the arguments.  The merged items are bulk-loaded
+// into the Map, which is much faster than inserting them one by one.
+// If a run yields a key that is less than the one it yielded before,
+// that item is inserted after the others are loaded, and its value
+// wins.  It takes O(k log r) time where k is the total number of the
+// items, and r is the number of runs.
+func CollectRuns[Key cmp.Ordered, Value any](
+	runs ...iter.Seq2[Key, Value],
+) *Map[Key, Value] {
+	h := make(runHeap[Key, Value], 0, len(runs))
+	stops := make([]func(), 0, len(runs))
+
+	defer func() {
+		for _, stop := range stops {
+			stop()
+		}
+	}()
+
+	for i, run := range runs {
+		next, stop := iter.Pull2(run)
+		stops = append(stops, stop)
+
+		if key, value, ok := next(); ok {
+			h = append(h, runHead[Key, Value]{
+				key:   key,
+				value: value,
+				idx:   i,
+				next:  next,
+			})
+		}
+	}
+
+	heap.Init(&h)
+
+	m := New[Key, Value]()
+	b := newBuilder(m)
+
+	var (
+		rest       []Entry[Key, Value]
+		pending    Entry[Key, Value]
+		hasPending bool
+	)
+
+	for len(h) > 0 {
+		head := &h[0]
+		key, value := head.key, head.value
+
+		if nkey, nvalue, ok := head.next(); ok {
+			head.key, head.value = nkey, nvalue
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+
+		if hasPending {
+			switch c := cmp.Compare(key, pending.Key); {
+			case c == 0:
+				pending.Value = value
+				continue
+			case c < 0:
+				rest = append(rest, Entry[Key, Value]{Key: key, Value: value})
+				continue
+			}
+
+			b.add(pending.Key, pending.Value)
+		}
+
+		pending = Entry[Key, Value]{Key: key, Value: value}
+		hasPending = true
+	}
+
+	if hasPending {
+		b.add(pending.Key, pending.Value)
+	}
+
+	b.finish()
+
+	for _, e := range rest {
+		m.Insert(e.Key, e.Value)
+	}
+
+	return m
+}
@@ -0,0 +1,154 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	for _, n := range []int{
+		0, 1, 15, 16, 31, 32, 33, 47, 48, 63, 64, 65, 1000, 1024, 1025,
+		32*32 + 1, 32*32*32 + 17,
+	} {
+		m := New[int, int]()
+		b := newBuilder(m)
+
+		for i := range n {
+			b.add(i, i+1)
+		}
+
+		b.finish()
+
+		verifyMap(t, m, 0, n-1)
+
+		assert.Equal(t, n, m.Len())
+		assert.Equal(t, slices.Collect(genIntSeq(n)),
+			slices.Collect(m.Keys()))
+
+		for i := 0; i < n; i += 2 {
+			m.Remove(i)
+		}
+
+		for i := n; i < n+100; i++ {
+			m.Insert(i, i+1)
+		}
+
+		verifyMap(t, m, 0, n+99)
+
+		for i := range n + 100 {
+			m.Remove(i)
+		}
+
+		verifyMap(t, m, 0, 0)
+
+		assert.Equal(t, 0, m.Len())
+	}
+}
+
+func TestBuilderNodePool(t *testing.T) {
+	m := New[int, int]()
+	m.UseNodePool()
+
+	for i := range 1000 {
+		m.Insert(i, i)
+	}
+
+	b := newBuilder(m)
+
+	for i := range 100 {
+		b.add(i*3, i)
+	}
+
+	b.finish()
+
+	verifyMap(t, m, 0, 297)
+
+	assert.Equal(t, slices.Collect(genIntSeqStep(0, 300, 3)),
+		slices.Collect(m.Keys()))
+}
+
+func TestCollectRuns(t *testing.T) {
+	m := CollectRuns[int, int]()
+
+	assert.Equal(t, 0, m.Len())
+
+	a := New[int, int]()
+	b := New[int, int]()
+	c := New[int, int]()
+
+	for i := range 1000 {
+		a.Insert(i*2, 1)
+		b.Insert(i*3, 2)
+		c.Insert(i*5+10000, 3)
+	}
+
+	m = CollectRuns(a.Begin().Seq(), New[int, int]().Begin().Seq(),
+		b.Begin().Seq(), c.Begin().Seq())
+
+	verifyMap(t, m, 0, 14995)
+
+	assert.Equal(t, 1000+1000-334+1000, m.Len())
+
+	for k, v := range m.Begin().Seq() {
+		switch {
+		case k >= 10000:
+			assert.Equal(t, 3, v)
+		case k%3 == 0:
+			assert.Equal(t, 2, v)
+		default:
+			assert.Equal(t, 1, v)
+		}
+	}
+
+	m = CollectRuns(b.Begin().Seq(), a.Begin().Seq())
+
+	v, ok := m.Find(6)
+
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	m = CollectRuns(func(yield func(int, int) bool) {
+		for i, k := range []int{1, 3, 3, 2, 5, 1} {
+			if !yield(k, i) {
+				return
+			}
+		}
+	}, func(yield func(int, int) bool) {
+		for i, k := range []int{0, 3, 4} {
+			if !yield(k, i+10) {
+				return
+			}
+		}
+	})
+
+	verifyMap(t, m, 0, 5)
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, slices.Collect(m.Keys()))
+	assert.Equal(t, []int{10, 5, 3, 11, 12, 4}, slices.Collect(m.Values()))
+}
@@ -0,0 +1,118 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+//go:build treemap_debug
+
+package treemap
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIteratorEndPanics(t *testing.T) {
+	m := New[int, int]()
+
+	assert.PanicsWithValue(t, "treemap: iterator at end", func() {
+		m.End().Key()
+	})
+
+	m.Insert(1, 1)
+
+	assert.PanicsWithValue(t, "treemap: iterator at end", func() {
+		m.End().Value()
+	})
+	assert.PanicsWithValue(t, "treemap: iterator at end", func() {
+		m.End().KeyValue()
+	})
+	assert.PanicsWithValue(t, "treemap: iterator at end", func() {
+		m.End().SetValue(2)
+	})
+	assert.PanicsWithValue(t, "treemap: iterator at end", func() {
+		m.REnd().Key()
+	})
+	assert.NotPanics(t, func() {
+		m.Begin().SetValue(2)
+	})
+}
+
+func TestMapInconsistentCompare(t *testing.T) {
+	m := NewAny[int, int](func(int, int) int {
+		return -1
+	})
+
+	m.Insert(1, 1)
+
+	assert.PanicsWithValue(t,
+		"treemap: compare is inconsistent: 1 must be less than 2",
+		func() {
+			m.Insert(2, 2)
+		})
+
+	// compare that ignores the sign of the keys.
+	m = NewAny[int, int](func(x, y int) int {
+		return cmp.Compare(max(x, -x), max(y, -y))
+	})
+
+	for i := range 100 {
+		m.Insert(i, i)
+	}
+
+	assert.NotPanics(t, func() {
+		m.Insert(-50, 0)
+	})
+
+	m = NewAny[int, int](func(x, y int) int {
+		if x < 0 || y < 0 {
+			return 1
+		}
+
+		return cmp.Compare(x, y)
+	})
+
+	for i := range 100 {
+		m.Insert(i, i)
+	}
+
+	assert.Panics(t, func() {
+		m.Insert(-50, 0)
+	})
+}
+
+func TestMapInconsistentSearch(t *testing.T) {
+	m := New[int, int]()
+
+	for i := range 10 {
+		m.Insert(i, i)
+	}
+
+	m.SetSearch(func(keys []int, _ int) (int, bool) {
+		return len(keys), false
+	})
+
+	assert.Panics(t, func() {
+		m.Insert(-1, 0)
+	})
+}
@@ -25,4 +25,25 @@
 // underlying implementation is based on B+ Tree.  It aims to
 // generally efficient insertion/removal/iteration, and fewer memory
 // allocations.
+//
+// If the program is built with treemap_debug build tag, the package
+// performs the extra checks that detect misuse of the API, such as
+// dereferencing the Iterator that points to the end, or the [Compare]
+// function that does not define a strict total order, and panics.
+// These checks slow down the operations, so they are disabled by
+// default.
+//
+// If the [Compare] function panics, the panic propagates to the
+// caller of the method of Map.  The Map is left valid, and the items
+// in it are unchanged by the interrupted operation, although its
+// nodes might have been split or merged in preparation for the
+// operation.  It can continue to be used after the panic is
+// recovered.  [Map.Validate] can be used to check this.
+//
+// The nodes do not record the number of items in their subtrees, so
+// that insertion and removal do not have to update them on the path
+// from the root.  Therefore, the positional operations, such as
+// [Map.KeyAt], [Map.IndexOf], [Map.Sample] and [CountPrefix], cannot
+// find a position in O(log n) time.  They walk the linked leaf nodes
+// instead, skipping a whole leaf node at a time.
 package treemap
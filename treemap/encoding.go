@@ -0,0 +1,349 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// errNoCompare is returned when the Map that is not created by [New]
+// or [NewAny], such as the zero value, is given to the decoder.
+var errNoCompare = errors.New(
+	"treemap: Map has no comparator; create it with New or NewAny")
+
+// MarshalText implements [encoding.TextMarshaler].  It emits one
+// key=value line per item in the sorted order.  Keys and values that
+// implement [encoding.TextMarshaler] are formatted by it.  Otherwise,
+// they are formatted by fmt with %v verb, which uses [fmt.Stringer]
+// if they implement it.  If [encoding.TextMarshaler] fails, it
+// returns the error.  [Map.UnmarshalText] cannot parse a key that
+// contains '=' or a newline, or a value that contains a newline, so
+// that it returns an error for such an item.
+func (m *Map[Key, Value]) MarshalText() ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	for it := m.Begin(); !it.End(); it = it.Next() {
+		start := len(b)
+
+		b, err = appendText(b, it.Key())
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.ContainsAny(b[start:], "=\n") {
+			return nil, fmt.Errorf("treemap: key %q contains '=' or a newline",
+				b[start:])
+		}
+
+		b = append(b, '=')
+		start = len(b)
+
+		b, err = appendText(b, it.Value())
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.IndexByte(b[start:], '\n') != -1 {
+			return nil, fmt.Errorf("treemap: value %q contains a newline",
+				b[start:])
+		}
+
+		b = append(b, '\n')
+	}
+
+	return b, nil
+}
+
+// appendText appends the text representation of v to dst.  If v
+// implements [encoding.TextMarshaler], it is used.  Otherwise, or if v
+// is a nil pointer, v is formatted by fmt with %v verb.
+func appendText(dst []byte, v any) ([]byte, error) {
+	tm, ok := v.(encoding.TextMarshaler)
+	if !ok || isNilPointer(v) {
+		return fmt.Append(dst, v), nil
+	}
+
+	text, err := tm.MarshalText()
+	if err != nil {
+		return dst, fmt.Errorf("treemap: %w", err)
+	}
+
+	return append(dst, text...), nil
+}
+
+// isNilPointer returns true if v is a nil pointer.  Calling
+// MarshalText with the value receiver on it panics.
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// appendString is [appendText] that falls back to %v verb if
+// [encoding.TextMarshaler] fails.
+func appendString(dst []byte, v any) []byte {
+	b, err := appendText(dst, v)
+	if err != nil {
+		return fmt.Append(dst, v)
+	}
+
+	return b
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].  It parses
+// text produced by [Map.MarshalText] and replaces the contents of m
+// with the parsed items.  Each line is split at the first '=', so keys
+// must not contain '=', and neither keys nor values may contain a
+// newline.  Key and Value must be string, bool, integer, or floating
+// point types, or implement [encoding.TextUnmarshaler].  Otherwise,
+// it returns an error.  If a key appears more than once, the value
+// that appears last wins.  The items are bulk-loaded as
+// [Map.ReplaceAll] does.  m is unchanged if an error is returned.  m
+// must be created by [New] or [NewAny]; otherwise, it returns an
+// error.
+func (m *Map[Key, Value]) UnmarshalText(text []byte) error {
+	if m.compare == nil {
+		return errNoCompare
+	}
+
+	var (
+		keys   []Key
+		values []Value
+	)
+
+	for line := range bytes.Lines(text) {
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		if len(line) == 0 {
+			continue
+		}
+
+		ks, vs, ok := strings.Cut(string(line), "=")
+		if !ok {
+			return fmt.Errorf("treemap: missing '=' in line %q", line)
+		}
+
+		var (
+			key   Key
+			value Value
+		)
+
+		if err := parseText(ks, &key); err != nil {
+			return err
+		}
+
+		if err := parseText(vs, &value); err != nil {
+			return err
+		}
+
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+
+	return m.ReplaceAll(keys, values)
+}
+
+// EncodeJSON writes the items of m to w as a JSON array of [key,
+// value] pairs in the sorted order.  It encodes the items with
+// [json.Marshal] and writes them one by one, so that the whole
+// encoding is not buffered in memory.  If writing to
+// w or encoding an item fails, it returns the error, and the partial
+// output is left in w.
+func (m *Map[Key, Value]) EncodeJSON(w io.Writer) error {
+	// b holds what precedes the next element: '[' before the first
+	// one, and ',' before the others.
+	b := []byte{'['}
+
+	for it := m.Begin(); !it.End(); it = it.Next() {
+		if len(b) == 0 {
+			b = append(b, ',')
+		}
+
+		elem, err := json.Marshal([2]any{it.Key(), it.Value()})
+		if err != nil {
+			return fmt.Errorf("treemap: %w", err)
+		}
+
+		if _, err := w.Write(append(b, elem...)); err != nil {
+			return fmt.Errorf("treemap: %w", err)
+		}
+
+		b = b[:0]
+	}
+
+	if _, err := w.Write(append(b, ']')); err != nil {
+		return fmt.Errorf("treemap: %w", err)
+	}
+
+	return nil
+}
+
+// DecodeJSON reads a JSON array of [key, value] pairs produced by
+// [Map.EncodeJSON] from r, and replaces the contents of m with the
+// decoded items.  The pairs need not be sorted.  If a key appears
+// more than once, the value that appears last wins.  The items are
+// bulk-loaded as [Map.ReplaceAll] does.  The data that follows the
+// array is ignored, but r may have been read past the array because
+// the input is buffered.  m is unchanged if an error is returned.  m
+// must be created by [New] or [NewAny]; otherwise, it returns an
+// error.
+func (m *Map[Key, Value]) DecodeJSON(r io.Reader) error {
+	if m.compare == nil {
+		return errNoCompare
+	}
+
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	var (
+		keys   []Key
+		values []Value
+	)
+
+	for dec.More() {
+		var pair []json.RawMessage
+
+		if err := dec.Decode(&pair); err != nil {
+			return fmt.Errorf("treemap: %w", err)
+		}
+
+		if len(pair) != 2 {
+			return fmt.Errorf("treemap: expected [key, value] pair, "+
+				"but got %d elements", len(pair))
+		}
+
+		var (
+			key   Key
+			value Value
+		)
+
+		if err := json.Unmarshal(pair[0], &key); err != nil {
+			return fmt.Errorf("treemap: %w", err)
+		}
+
+		if err := json.Unmarshal(pair[1], &value); err != nil {
+			return fmt.Errorf("treemap: %w", err)
+		}
+
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+
+	if err := expectDelim(dec, ']'); err != nil {
+		return err
+	}
+
+	return m.ReplaceAll(keys, values)
+}
+
+// expectDelim reads the next token from dec, and returns an error if
+// it is not delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("treemap: %w", err)
+	}
+
+	if tok != delim {
+		return fmt.Errorf("treemap: expected %v, but got %v", delim, tok)
+	}
+
+	return nil
+}
+
+// parseText parses s and stores the result in the value pointed by p.
+func parseText[T any](s string, p *T) error {
+	var err error
+
+	switch v := any(p).(type) {
+	case encoding.TextUnmarshaler:
+		err = v.UnmarshalText([]byte(s))
+	case *string:
+		*v = s
+	case *bool:
+		*v, err = strconv.ParseBool(s)
+	case *int:
+		*v, err = strconv.Atoi(s)
+	case *int8:
+		*v, err = parseInt[int8](s, 8)
+	case *int16:
+		*v, err = parseInt[int16](s, 16)
+	case *int32:
+		*v, err = parseInt[int32](s, 32)
+	case *int64:
+		*v, err = strconv.ParseInt(s, 10, 64)
+	case *uint:
+		*v, err = parseUint[uint](s, strconv.IntSize)
+	case *uint8:
+		*v, err = parseUint[uint8](s, 8)
+	case *uint16:
+		*v, err = parseUint[uint16](s, 16)
+	case *uint32:
+		*v, err = parseUint[uint32](s, 32)
+	case *uint64:
+		*v, err = strconv.ParseUint(s, 10, 64)
+	case *float32:
+		var f float64
+
+		f, err = strconv.ParseFloat(s, 32)
+		*v = float32(f)
+	case *float64:
+		*v, err = strconv.ParseFloat(s, 64)
+	default:
+		return fmt.Errorf("treemap: cannot parse text into %T", *p)
+	}
+
+	if err != nil {
+		return fmt.Errorf("treemap: %w", err)
+	}
+
+	return nil
+}
+
+func parseInt[T ~int8 | ~int16 | ~int32](s string, bitSize int) (T, error) {
+	n, err := strconv.ParseInt(s, 10, bitSize)
+
+	return T(n), err
+}
+
+func parseUint[T ~uint | ~uint8 | ~uint16 | ~uint32](
+	s string, bitSize int,
+) (T, error) {
+	n, err := strconv.ParseUint(s, 10, bitSize)
+
+	return T(n), err
+}
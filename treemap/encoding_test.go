@@ -0,0 +1,264 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapMarshalText(t *testing.T) {
+	m := New[string, int]()
+
+	b, err := m.MarshalText()
+
+	require.NoError(t, err)
+	assert.Empty(t, b)
+
+	m.Insert("foo", 1)
+	m.Insert("bar", 2)
+	m.Insert("baz", -3)
+
+	b, err = m.MarshalText()
+
+	require.NoError(t, err)
+	assert.Equal(t, "bar=2\nbaz=-3\nfoo=1\n", string(b))
+
+	m2 := New[string, int]()
+
+	m2.Insert("qux", 4)
+
+	require.NoError(t, m2.UnmarshalText(b))
+	assert.Equal(t, []string{"bar", "baz", "foo"},
+		slices.Collect(m2.Keys()))
+	assert.Equal(t, []int{2, -3, 1}, slices.Collect(m2.Values()))
+
+	// The items that UnmarshalText cannot parse back are rejected.
+	for _, tc := range []struct {
+		key, value string
+	}{
+		{"a=b", "c"},
+		{"a\nb", "c"},
+		{"x", "line1\nline2"},
+	} {
+		s := New[string, string]()
+
+		s.Insert(tc.key, tc.value)
+
+		_, err := s.MarshalText()
+
+		require.Error(t, err)
+	}
+
+	// '=' in a value and other special characters round-trip.
+	s := New[string, string]()
+
+	s.Insert("a b", "c=d")
+	s.Insert("e\tf", "\r")
+
+	b, err = s.MarshalText()
+
+	require.NoError(t, err)
+
+	s2 := New[string, string]()
+
+	require.NoError(t, s2.UnmarshalText(b))
+	assert.Equal(t, Collect(s.Begin().Seq()), Collect(s2.Begin().Seq()))
+}
+
+// textKey implements both encoding.TextMarshaler and fmt.Stringer
+// with the different representations.  MarshalText fails if it is
+// negative.
+type textKey int
+
+func (k textKey) MarshalText() ([]byte, error) {
+	if k < 0 {
+		return nil, errors.New("negative")
+	}
+
+	return fmt.Appendf(nil, "t%d", int(k)), nil
+}
+
+func (k textKey) String() string {
+	return fmt.Sprintf("s%d", int(k))
+}
+
+// stringValue implements fmt.Stringer only.
+type stringValue int
+
+func (v stringValue) String() string {
+	return fmt.Sprintf("v%d", int(v))
+}
+
+func TestMapMarshalTextMarshaler(t *testing.T) {
+	m := New[textKey, stringValue]()
+
+	m.Insert(1, 10)
+	m.Insert(2, 20)
+
+	b, err := m.MarshalText()
+
+	require.NoError(t, err)
+	assert.Equal(t, "t1=v10\nt2=v20\n", string(b))
+	assert.Equal(t, "Map[t1:v10 t2:v20]", m.String())
+
+	m.Insert(-1, 0)
+
+	_, err = m.MarshalText()
+
+	require.EqualError(t, err, "treemap: negative")
+	assert.Equal(t, "Map[s-1:v0 t1:v10 t2:v20]", m.String())
+}
+
+func TestMapUnmarshalText(t *testing.T) {
+	m := New[int, float64]()
+
+	require.NoError(t, m.UnmarshalText([]byte("3=1.5\n1=-2\n\n2=1e3")))
+	assert.Equal(t, []int{1, 2, 3}, slices.Collect(m.Keys()))
+	assert.Equal(t, []float64{-2, 1000, 1.5}, slices.Collect(m.Values()))
+
+	require.Error(t, m.UnmarshalText([]byte("4=1\n5")))
+	require.Error(t, m.UnmarshalText([]byte("a=1")))
+	require.Error(t, m.UnmarshalText([]byte("4=a")))
+
+	// m is unchanged on error.
+	assert.Equal(t, []int{1, 2, 3}, slices.Collect(m.Keys()))
+
+	addrs := NewAny[netip.Addr, uint8](netip.Addr.Compare)
+
+	require.NoError(t, addrs.UnmarshalText(
+		[]byte("192.0.2.1=1\n2001:db8::1=2\n")))
+
+	b, err := addrs.MarshalText()
+
+	require.NoError(t, err)
+	assert.Equal(t, "192.0.2.1=1\n2001:db8::1=2\n", string(b))
+
+	require.Error(t, addrs.UnmarshalText([]byte("192.0.2.1=256\n")))
+
+	type unsupported struct{}
+
+	m2 := New[string, unsupported]()
+
+	require.Error(t, m2.UnmarshalText([]byte("foo={}\n")))
+
+	require.NoError(t, m.UnmarshalText([]byte("2=1\n1=2\n2=3\n")))
+	assert.Equal(t, []int{1, 2}, slices.Collect(m.Keys()))
+	assert.Equal(t, []float64{2, 3}, slices.Collect(m.Values()))
+
+	var zero Map[int, int]
+
+	require.ErrorIs(t, zero.UnmarshalText([]byte("1=1\n")), errNoCompare)
+
+	// m is unchanged if compare panics during the bulk-load.
+	p := NewAny[int, int](func(x, y int) int {
+		if x == 13 || y == 13 {
+			panic("compare")
+		}
+
+		return cmp.Compare(x, y)
+	})
+
+	p.Insert(1, 1)
+
+	assert.Panics(t, func() {
+		_ = p.UnmarshalText([]byte("2=2\n13=13\n3=3\n"))
+	})
+	assert.Equal(t, []int{1}, slices.Collect(p.Keys()))
+}
+
+func TestMapEncodeJSON(t *testing.T) {
+	m := New[int, string]()
+
+	var buf bytes.Buffer
+
+	require.NoError(t, m.EncodeJSON(&buf))
+	assert.Equal(t, "[]", buf.String())
+
+	for i := range 1000 {
+		m.Insert(i, strconv.Itoa(i))
+	}
+
+	buf.Reset()
+
+	require.NoError(t, m.EncodeJSON(&buf))
+	assert.NotContains(t, buf.String(), "\n")
+	assert.True(t, strings.HasPrefix(buf.String(), `[[0,"0"],[1,"1"],`))
+
+	var pairs [][2]any
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &pairs))
+	require.Len(t, pairs, 1000)
+	assert.Equal(t, [2]any{float64(0), "0"}, pairs[0])
+	assert.Equal(t, [2]any{float64(999), "999"}, pairs[999])
+
+	m2 := New[int, string]()
+
+	m2.Insert(-1, "x")
+
+	require.NoError(t, m2.DecodeJSON(&buf))
+
+	verifyMap(t, m2, 0, 999)
+
+	assert.Equal(t, slices.Collect(m.Keys()), slices.Collect(m2.Keys()))
+	assert.Equal(t, slices.Collect(m.Values()), slices.Collect(m2.Values()))
+}
+
+func TestMapDecodeJSON(t *testing.T) {
+	m := New[string, int]()
+
+	require.NoError(t, m.DecodeJSON(strings.NewReader(
+		`[["foo",1],["bar",2],["foo",3]] trailing`)))
+	assert.Equal(t, []string{"bar", "foo"}, slices.Collect(m.Keys()))
+	assert.Equal(t, []int{2, 3}, slices.Collect(m.Values()))
+
+	for _, s := range []string{
+		``,
+		`{}`,
+		`[["foo"]]`,
+		`[["foo",1,2]]`,
+		`[[1,1]]`,
+		`[["foo","bar"]]`,
+		`[["foo",1]`,
+	} {
+		require.Error(t, m.DecodeJSON(strings.NewReader(s)), s)
+		assert.Equal(t, []string{"bar", "foo"}, slices.Collect(m.Keys()))
+	}
+
+	var zero Map[string, int]
+
+	require.ErrorIs(t, zero.DecodeJSON(strings.NewReader(`[["foo",1]]`)),
+		errNoCompare)
+}
@@ -39,21 +39,50 @@ type Iterator[Key, Value any] struct {
 // Key returns the key pointed by it.  This function must not be
 // called if [Iterator.End] returns true.
 func (it Iterator[Key, Value]) Key() Key {
+	if debug {
+		it.mustNotEnd()
+	}
+
 	return it.node.keys[it.idx]
 }
 
 // Value returns the value pointed by it.  This function must not be
 // called if [Iterator.End] returns true.
 func (it Iterator[Key, Value]) Value() Value {
+	if debug {
+		it.mustNotEnd()
+	}
+
 	return it.node.values[it.idx]
 }
 
+// KeyValue returns the key and value pointed by it.  This function
+// must not be called if [Iterator.End] returns true.
+func (it Iterator[Key, Value]) KeyValue() (Key, Value) {
+	if debug {
+		it.mustNotEnd()
+	}
+
+	return it.node.keys[it.idx], it.node.values[it.idx]
+}
+
 // SetValue sets value to the current position.  This function must
 // not be called if [Iterator.End] returns true.
 func (it Iterator[Key, Value]) SetValue(value Value) {
+	if debug {
+		it.mustNotEnd()
+	}
+
 	it.node.values[it.idx] = value
 }
 
+// mustNotEnd panics if it does not point to an item.
+func (it Iterator[Key, Value]) mustNotEnd() {
+	if it.idx < 0 || it.idx >= it.node.n {
+		panic("treemap: iterator at end")
+	}
+}
+
 // Begin returns true if it points to the first item.
 func (it Iterator[Key, Value]) Begin() bool {
 	return it.idx == 0 && it.node.prev == nil
@@ -64,6 +93,26 @@ func (it Iterator[Key, Value]) End() bool {
 	return it.node.n == it.idx && it.node.next == nil
 }
 
+// REnd returns true if it points to the one before the first item.
+func (it Iterator[Key, Value]) REnd() bool {
+	return it.idx == -1
+}
+
+// Equal returns true if it and o point to the same position.  Both
+// must be obtained from the same [Map] and not be invalidated.
+func (it Iterator[Key, Value]) Equal(o Iterator[Key, Value]) bool {
+	return it == o
+}
+
+// Clone returns the Iterator that points to the same position as it.
+// The returned Iterator moves independently of it, so that one of
+// them can keep a position while the other advances.  Iterator is a
+// value type, and Clone is equivalent to copying it, but it states the
+// intent.
+func (it Iterator[Key, Value]) Clone() Iterator[Key, Value] {
+	return it
+}
+
 // Next returns the Iterator that points to the next item.  This
 // function must not be called if [Iterator.End] returns true.
 func (it Iterator[Key, Value]) Next() Iterator[Key, Value] {
@@ -77,10 +126,17 @@ func (it Iterator[Key, Value]) Next() Iterator[Key, Value] {
 	return it
 }
 
-// Prev returns the Iterator that points to the previous item.  This
-// function must not be called if [Iterator.Begin] returns true.
+// Prev returns the Iterator that points to the previous item.  If
+// [Iterator.Begin] returns true, it returns the Iterator whose
+// [Iterator.REnd] returns true.  This function must not be called if
+// [Iterator.REnd] returns true.
 func (it Iterator[Key, Value]) Prev() Iterator[Key, Value] {
 	if it.idx == 0 {
+		if it.node.prev == nil {
+			it.idx = -1
+			return it
+		}
+
 		it.node = it.node.prev
 		it.idx = it.node.n - 1
 	} else {
@@ -90,9 +146,14 @@ func (it Iterator[Key, Value]) Prev() Iterator[Key, Value] {
 	return it
 }
 
-// Seq returns Go iterator.
+// Seq returns Go iterator that yields the items from it to the end.
+// If [Iterator.REnd] returns true, it yields nothing.
 func (it Iterator[Key, Value]) Seq() iter.Seq2[Key, Value] {
 	return func(yield func(Key, Value) bool) {
+		if it.REnd() {
+			return
+		}
+
 		for ; !it.End(); it = it.Next() {
 			if !yield(it.Key(), it.Value()) {
 				return
@@ -100,3 +161,91 @@ func (it Iterator[Key, Value]) Seq() iter.Seq2[Key, Value] {
 		}
 	}
 }
+
+// Drop returns the Iterator that is advanced n positions from it.  If
+// there are fewer than n items after it, it returns the Iterator
+// whose [Iterator.End] returns true.  If n is not positive, it
+// returns it.  If [Iterator.REnd] returns true, it returns it as the
+// other helpers treat it as empty.  It skips whole leaf nodes, so that
+// it takes O(n/b) time where b is the number of items in a leaf node.
+func (it Iterator[Key, Value]) Drop(n int) Iterator[Key, Value] {
+	if it.REnd() {
+		return it
+	}
+
+	for n > 0 {
+		rem := it.node.n - it.idx
+		if n < rem {
+			it.idx += n
+			break
+		}
+
+		if it.node.next == nil {
+			it.idx = it.node.n
+			break
+		}
+
+		n -= rem
+		it.node = it.node.next
+		it.idx = 0
+	}
+
+	return it
+}
+
+// Take returns Go iterator that yields at most n items from it.  If
+// [Iterator.REnd] returns true, it yields nothing.
+func (it Iterator[Key, Value]) Take(n int) iter.Seq2[Key, Value] {
+	return func(yield func(Key, Value) bool) {
+		if it.REnd() {
+			return
+		}
+
+		for i := 0; i < n && !it.End(); i++ {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+
+			it = it.Next()
+		}
+	}
+}
+
+// Collect returns the keys and values from it to the end in the
+// sorted order.  If [Iterator.REnd] returns true, it returns empty
+// slices.
+func (it Iterator[Key, Value]) Collect() ([]Key, []Value) {
+	n := it.remaining()
+	keys := make([]Key, 0, n)
+	values := make([]Value, 0, n)
+
+	if it.REnd() {
+		return keys, values
+	}
+
+	idx := it.idx
+
+	for tnode := it.node; tnode != nil; tnode = tnode.next {
+		keys = append(keys, tnode.keys[idx:tnode.n]...)
+		values = append(values, tnode.values[idx:tnode.n]...)
+		idx = 0
+	}
+
+	return keys, values
+}
+
+// remaining returns the number of items from it to the end.  If
+// [Iterator.REnd] returns true, it returns 0.
+func (it Iterator[Key, Value]) remaining() int {
+	if it.REnd() {
+		return 0
+	}
+
+	n := -it.idx
+
+	for tnode := it.node; tnode != nil; tnode = tnode.next {
+		n += tnode.n
+	}
+
+	return n
+}
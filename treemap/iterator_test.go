@@ -93,6 +93,100 @@ func Collect[Key, Value any](
 	return s
 }
 
+func TestIteratorReverse(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Equal(t, m.REnd(), m.RBegin())
+	assert.True(t, m.RBegin().REnd())
+
+	for i := range 100 {
+		m.Insert(i, i+1)
+	}
+
+	key := 99
+
+	for it := m.RBegin(); it != m.REnd(); it = it.Prev() {
+		assert.False(t, it.REnd())
+		assert.Equal(t, key, it.Key())
+		assert.Equal(t, key+1, it.Value())
+
+		key--
+	}
+
+	assert.Equal(t, -1, key)
+
+	it := m.REnd()
+
+	assert.True(t, it.REnd())
+	assert.False(t, it.Begin())
+	assert.False(t, it.End())
+	assert.Equal(t, m.Begin(), it.Next())
+	assert.Equal(t, m.REnd(), m.Begin().Prev())
+	assert.Equal(t, m.RBegin(), m.End().Prev())
+}
+
+func TestIteratorREndHelpers(t *testing.T) {
+	// Every helper treats the Iterator from REnd as empty.
+	helpers := []struct {
+		name  string
+		count func(m *Map[int, int], it Iterator[int, int]) int
+	}{
+		{
+			name: "Seq",
+			count: func(_ *Map[int, int], it Iterator[int, int]) int {
+				return len(Collect(it.Seq()))
+			},
+		},
+		{
+			name: "Take",
+			count: func(_ *Map[int, int], it Iterator[int, int]) int {
+				return len(Collect(it.Take(10)))
+			},
+		},
+		{
+			name: "Collect",
+			count: func(_ *Map[int, int], it Iterator[int, int]) int {
+				keys, values := it.Collect()
+
+				return len(keys) + len(values)
+			},
+		},
+		{
+			name: "Drop",
+			count: func(m *Map[int, int], it Iterator[int, int]) int {
+				return m.CountFrom(it.Drop(1)) + len(Collect(it.Drop(1).Seq()))
+			},
+		},
+		{
+			name: "CountFrom",
+			count: func(m *Map[int, int], it Iterator[int, int]) int {
+				return m.CountFrom(it)
+			},
+		},
+	}
+
+	for _, n := range []int{0, 1, 100} {
+		m := New[int, int]()
+
+		for i := range n {
+			m.Insert(i, i+1)
+		}
+
+		it := m.REnd()
+
+		for _, h := range helpers {
+			assert.Equal(t, 0, h.count(m, it), "%s with %d items", h.name, n)
+		}
+
+		assert.Equal(t, it, it.Clone())
+		assert.Equal(t, it, it.Drop(0))
+		assert.Equal(t, it, it.Drop(1))
+		assert.Equal(t, it, it.Drop(n+1))
+		assert.Equal(t, it, m.RemoveIter(it))
+		assert.Equal(t, n, m.Len())
+	}
+}
+
 func TestIteratorSeq(t *testing.T) {
 	m := New[int, string]()
 
@@ -122,6 +216,24 @@ func TestIteratorSeq(t *testing.T) {
 	}
 }
 
+func TestIteratorKeyValue(t *testing.T) {
+	m := New[int, string]()
+
+	m.Insert(0, "foo")
+	m.Insert(1, "bar")
+
+	it := m.Begin()
+	k, v := it.KeyValue()
+
+	assert.Equal(t, 0, k)
+	assert.Equal(t, "foo", v)
+
+	k, v = it.Next().KeyValue()
+
+	assert.Equal(t, 1, k)
+	assert.Equal(t, "bar", v)
+}
+
 func TestIteratorSetValue(t *testing.T) {
 	m := New[int, string]()
 
@@ -141,3 +253,110 @@ func TestIteratorSetValue(t *testing.T) {
 	assert.Equal(t, []string{"foo", "BAR", "baz"},
 		slices.Collect(m.Values()))
 }
+
+func TestIteratorClone(t *testing.T) {
+	m := New[int, int]()
+
+	for i := range 100 {
+		m.Insert(i, i+1)
+	}
+
+	it := m.Begin().Drop(10)
+	saved := it.Clone()
+
+	assert.True(t, saved.Equal(it))
+
+	for range 50 {
+		it = it.Next()
+	}
+
+	assert.Equal(t, 60, it.Key())
+	assert.Equal(t, 10, saved.Key())
+
+	saved = saved.Prev()
+
+	assert.Equal(t, 9, saved.Key())
+	assert.Equal(t, 60, it.Key())
+	assert.Equal(t, []int{9, 10, 11}, collectKeys(saved.Take(3)))
+	assert.Equal(t, []int{60, 61, 62}, collectKeys(it.Take(3)))
+}
+
+func TestIteratorDrop(t *testing.T) {
+	m := New[int, int]()
+
+	assert.True(t, m.Begin().Drop(10).End())
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
+
+	for _, n := range []int{0, 1, 15, 16, 31, 32, 33, 500, 999} {
+		it := m.Begin()
+
+		for range n {
+			it = it.Next()
+		}
+
+		assert.Equal(t, it, m.Begin().Drop(n), "n=%d", n)
+		assert.Equal(t, n, m.Begin().Drop(n).Key())
+	}
+
+	assert.Equal(t, m.Begin(), m.Begin().Drop(-1))
+	assert.True(t, m.Begin().Drop(1000).End())
+	assert.True(t, m.Begin().Drop(5000).End())
+	assert.Equal(t, m.End(), m.LowerBound(990).Drop(10))
+	assert.Equal(t, m.End(), m.End().Drop(1))
+	assert.Equal(t, m.REnd(), m.REnd().Drop(1))
+	assert.Equal(t, m.LowerBound(600), m.LowerBound(100).Drop(500))
+}
+
+func TestIteratorTake(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Empty(t, Collect(m.Begin().Take(10)))
+
+	for i := range 100 {
+		m.Insert(i, i+1)
+	}
+
+	assert.Empty(t, Collect(m.Begin().Take(0)))
+	assert.Empty(t, Collect(m.Begin().Take(-1)))
+	assert.Equal(t, Collect(m.Range(0, 10)), Collect(m.Begin().Take(10)))
+	assert.Equal(t, Collect(m.Range(95, 100)),
+		Collect(m.LowerBound(95).Take(10)))
+	assert.Empty(t, Collect(m.End().Take(10)))
+
+	for range m.Begin().Take(10) {
+		break
+	}
+}
+
+func TestIteratorCollect(t *testing.T) {
+	m := New[int, int]()
+
+	keys, values := m.Begin().Collect()
+
+	assert.Empty(t, keys)
+	assert.Empty(t, values)
+
+	for i := range 100 {
+		m.Insert(i, i+1)
+	}
+
+	keys, values = m.Begin().Collect()
+
+	assert.Equal(t, slices.Collect(genIntSeq(100)), keys)
+	assert.Equal(t, slices.Collect(genIntSeq(101))[1:], values)
+
+	keys, values = m.LowerBound(40).Collect()
+
+	assert.Equal(t, slices.Collect(genIntSeq(100))[40:], keys)
+	assert.Equal(t, slices.Collect(genIntSeq(101))[41:], values)
+	assert.Len(t, keys, cap(keys))
+	assert.Len(t, values, cap(values))
+
+	keys, values = m.End().Collect()
+
+	assert.Empty(t, keys)
+	assert.Empty(t, values)
+}
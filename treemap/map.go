@@ -25,10 +25,24 @@ package treemap
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
 	"iter"
+	"maps"
+	"math/rand/v2"
 	"slices"
-	"strings"
+	"sort"
+	"sync"
+	"unsafe"
+)
+
+var (
+	// ErrOutOfOrder is returned when the keys given to the function
+	// that requires sorted keys are not in the increasing order.
+	ErrOutOfOrder = errors.New("treemap: keys are out of order")
+	// ErrDuplicateKey is returned when the keys given to the function
+	// that requires distinct keys contain the duplicates.
+	ErrDuplicateKey = errors.New("treemap: duplicate key")
 )
 
 // Compare is the function to compare x and y.  If x is less than y,
@@ -37,7 +51,12 @@ import (
 // return 0.
 type Compare[Key any] func(x, y Key) int
 
-type search[Key any] func([]Key, Key) (int, bool)
+// Search is the function to search target in the sorted keys in a
+// node.  It must return the position of the first key that is not
+// less than target under the [Compare] function of [Map], and true if
+// the key equals to target.  It is the same contract as
+// [slices.BinarySearchFunc].
+type Search[Key any] func(keys []Key, target Key) (int, bool)
 
 // Map is the sorted, key-value storage.
 type Map[Key, Value any] struct {
@@ -46,7 +65,52 @@ type Map[Key, Value any] struct {
 	back    *leafNode[Key, Value]
 	n       int
 	compare func(lhs, rhs Key) int
-	search  search[Key]
+	search  Search[Key]
+	pool    *nodePool
+	// height is the number of the levels of internal nodes.  The
+	// nodes at depth height are leaf nodes.
+	height int
+	// cache is the leaf node that is accessed last if useLeafCache
+	// is true.
+	cache        *leafNode[Key, Value]
+	useLeafCache bool
+	// minSize is the minimum number of items or children that a
+	// non-root node must have.  Removal rebalances the tree to keep
+	// it.  It must not exceed minNodes so that merged nodes fit.
+	minSize int
+	// splits is the number of the node splits performed so far.
+	splits int
+}
+
+// Entry is the key-value pair stored in [Map].
+type Entry[Key, Value any] struct {
+	Key   Key
+	Value Value
+}
+
+// Stats is the statistics of the nodes in [Map].
+type Stats struct {
+	// Leaves is the number of leaf nodes.
+	Leaves int
+	// Internals is the number of internal nodes.
+	Internals int
+	// Height is the number of internal nodes from the root to a leaf
+	// node.
+	Height int
+	// Capacity is the total number of slots in the nodes.
+	Capacity int
+	// Used is the number of the occupied slots, that is the sum of
+	// the number of items in the leaf nodes and the number of
+	// children in the internal nodes.
+	Used int
+	// FillRatio is Used divided by Capacity.
+	FillRatio float64
+}
+
+// nodePool keeps the nodes that are removed from the tree for reuse.
+type nodePool struct {
+	leaves    sync.Pool
+	internals sync.Pool
 }
 
 // New returns new Map for the ordered keys.
@@ -59,13 +123,85 @@ func New[Key cmp.Ordered, Value any]() *Map[Key, Value] {
 		back:    node,
 		compare: cmp.Compare[Key],
 		search:  linearSearchOrdered[Key],
+		minSize: minNodes,
+	}
+}
+
+// FromMap returns new Map that contains all key-value pairs in src.
+// The iteration order of src does not matter because the keys are
+// sorted once, and the tree is built bottom-up as [Map.ReplaceAll]
+// does, which is faster than inserting the pairs one by one.  src may
+// contain more than one NaN key, but they are equal under
+// [cmp.Compare], and only one of them, chosen arbitrarily, is kept.
+func FromMap[Key cmp.Ordered, Value any](src map[Key]Value) *Map[Key, Value] {
+	m := New[Key, Value]()
+	keys := make([]Key, 0, len(src))
+	values := make([]Value, 0, len(src))
+
+	for k, v := range src {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+
+	// The lengths of keys and values are the same.
+	_ = m.ReplaceAll(keys, values)
+
+	return m
+}
+
+// ToMap returns new built-in map that contains all key-value pairs in
+// m.  It is a function rather than a method of [Map] because Key
+// must be comparable.
+func ToMap[Key comparable, Value any](m *Map[Key, Value]) map[Key]Value {
+	dst := make(map[Key]Value, m.n)
+
+	for tnode := m.front; tnode != nil; tnode = tnode.next {
+		for i, key := range tnode.keys[:tnode.n] {
+			dst[key] = tnode.values[i]
+		}
+	}
+
+	return dst
+}
+
+// integer is the constraint that permits any integer type.
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// DeltaKeys returns an iterator that yields the first key in m, and
+// then the difference between each key and the previous one in the
+// sorted order.  It is suitable for delta encoding of the keys.  The
+// difference may overflow Key if the keys are far apart, but the
+// original keys are still recovered by adding the yielded values
+// with the same wraparound arithmetic.
+func DeltaKeys[Key integer, Value any](m *Map[Key, Value]) iter.Seq[Key] {
+	return func(yield func(Key) bool) {
+		var prev Key
+
+		for key := range m.Keys() {
+			if !yield(key - prev) {
+				return
+			}
+
+			prev = key
+		}
 	}
 }
 
 // linearSearchOrdered searches target in keys in O(n).  With keyDegr
 // = 16, linear search is faster than binary search for cmp.Ordered
-// types.
+// types.  Like [cmp.Compare], it treats NaN as less than any other
+// value and equal to NaN.
 func linearSearchOrdered[Key cmp.Ordered](keys []Key, target Key) (int, bool) {
+	// target is NaN.  NaN can only be at the beginning of keys.  If
+	// key is NaN, neither of the comparisons in the loop holds, which
+	// correctly treats it as less than target.
+	if target != target {
+		return 0, len(keys) > 0 && keys[0] != keys[0]
+	}
+
 	for i, key := range keys {
 		switch {
 		case key == target:
@@ -80,10 +216,14 @@ func linearSearchOrdered[Key cmp.Ordered](keys []Key, target Key) (int, bool) {
 
 // NewAny returns new Map with custom [Compare] function.  [New]
 // should be used for Key that is of type [cmp.Ordered] because it is
-// much more efficient.
+// much more efficient.  It panics if compare is nil.
 func NewAny[Key, Value any](
 	compare Compare[Key],
 ) *Map[Key, Value] {
+	if compare == nil {
+		panic("treemap: NewAny called with nil compare")
+	}
+
 	node := &leafNode[Key, Value]{}
 
 	return &Map[Key, Value]{
@@ -91,9 +231,233 @@ func NewAny[Key, Value any](
 		front:   node,
 		back:    node,
 		compare: compare,
-		search: func(keys []Key, target Key) (int, bool) {
-			return slices.BinarySearchFunc(keys, target, compare)
-		},
+		search:  binarySearchFunc(compare),
+		minSize: minNodes,
+	}
+}
+
+// binarySearchFunc returns the function that searches target in keys
+// in O(log n) using compare.
+func binarySearchFunc[Key any](compare Compare[Key]) Search[Key] {
+	return func(keys []Key, target Key) (int, bool) {
+		return slices.BinarySearchFunc(keys, target, compare)
+	}
+}
+
+// UseBinarySearch makes m search keys in a node with binary search.
+// By default, [New] uses linear search which is faster for small
+// keys, but binary search might win for the keys that are expensive
+// to compare (e.g., long strings with common prefix).  Maps created
+// by [NewAny] always use binary search.  It is safe to call this
+// function on the non-empty m.
+func (m *Map[Key, Value]) UseBinarySearch() {
+	m.search = binarySearchFunc(m.compare)
+}
+
+// SetSearch makes m search keys in a node with search.  It is
+// intended for benchmarking the search strategies for a workload.
+// search must agree with the [Compare] function of m; otherwise, the
+// behavior is undefined.  If the program is built with treemap_debug
+// build tag, the results of search during insertion are verified
+// against the [Compare] function, and the disagreement causes a
+// panic.  It is safe to call this function on the non-empty m.
+func (m *Map[Key, Value]) SetSearch(search Search[Key]) {
+	if search == nil {
+		panic("treemap: SetSearch called with nil search")
+	}
+
+	m.search = search
+}
+
+// UseNodePool makes m reuse the nodes that are removed from the tree
+// by merge or [Map.Clear] when it needs new nodes.  It reduces memory
+// allocations for the workloads that constantly grow and shrink m.
+// The pooled nodes are released by garbage collector if they are not
+// reused.  It is safe to call this function on the non-empty m.
+func (m *Map[Key, Value]) UseNodePool() {
+	if m.pool == nil {
+		m.pool = &nodePool{}
+	}
+}
+
+// UseLazyRebalance makes m allow nodes to have much fewer items
+// before removal rebalances them.  It reduces the number of merges,
+// and so the node allocations, for the workloads that remove items
+// heavily or alternate insertion and removal, at the expense of
+// memory and lookup locality.  It does not necessarily make them
+// faster.  [Map.Compact] packs the sparse nodes.  It is safe to call
+// this function on the non-empty m.
+func (m *Map[Key, Value]) UseLazyRebalance() {
+	m.minSize = lazyMinNodes
+}
+
+func (m *Map[Key, Value]) newLeafNode() *leafNode[Key, Value] {
+	if m.pool != nil {
+		if tnode, ok := m.pool.leaves.Get().(*leafNode[Key, Value]); ok {
+			return tnode
+		}
+	}
+
+	return &leafNode[Key, Value]{}
+}
+
+func (m *Map[Key, Value]) newInternalNode() *internalNode[Key, Value] {
+	if m.pool != nil {
+		if inode, ok := m.pool.internals.Get().(*internalNode[Key, Value]); ok {
+			return inode
+		}
+	}
+
+	return &internalNode[Key, Value]{}
+}
+
+// freeNode returns node to the pool if it is enabled.  node must not
+// be referenced from the tree.
+func (m *Map[Key, Value]) freeNode(node node[Key, Value]) {
+	if m.pool == nil {
+		return
+	}
+
+	switch node := node.(type) {
+	case *internalNode[Key, Value]:
+		*node = internalNode[Key, Value]{}
+		m.pool.internals.Put(node)
+	case *leafNode[Key, Value]:
+		*node = leafNode[Key, Value]{}
+		m.pool.leaves.Put(node)
+	}
+}
+
+// freeTree returns all nodes under node to the pool.
+func (m *Map[Key, Value]) freeTree(node node[Key, Value]) {
+	if inode, ok := node.(*internalNode[Key, Value]); ok {
+		for _, node := range inode.nodes[:inode.n] {
+			m.freeTree(node)
+		}
+	}
+
+	m.freeNode(node)
+}
+
+// UseLeafCache makes m remember the leaf node that [Map.Insert]
+// accessed last.  If the next key given to [Map.Insert] or [Map.Find]
+// falls in the range of that leaf, they skip the descent from the
+// root.  It benefits the workloads that access nearby keys
+// successively, such as inserting monotonically increasing keys.
+// Otherwise, it only adds a few comparisons to each call.  [Map.Find]
+// only reads the cache, so that concurrent readers, such as those of
+// [ReadOnlyMap], do not race.
+func (m *Map[Key, Value]) UseLeafCache() {
+	m.useLeafCache = true
+}
+
+// insertCached is [Map.insert] for the cached leaf node.  It works
+// only if key belongs to the cached leaf node.  The last return value
+// is false if key does not belong to it, and nothing is done.
+func (m *Map[Key, Value]) insertCached(
+	key Key, value Value,
+) (Iterator[Key, Value], bool, bool) {
+	tnode := m.cache
+	if tnode == nil || tnode.n == 0 || tnode.IsFull() ||
+		m.compare(key, tnode.keys[0]) < 0 {
+		return Iterator[Key, Value]{}, false, false
+	}
+
+	if m.compare(tnode.LastKey(), key) < 0 {
+		if tnode != m.back {
+			return Iterator[Key, Value]{}, false, false
+		}
+
+		m.extendBack(key)
+
+		idx := tnode.n
+		tnode.InsertAt(idx, key, value)
+
+		m.n++
+
+		return Iterator[Key, Value]{
+			node: tnode,
+			idx:  idx,
+		}, false, true
+	}
+
+	i, ok := m.search(tnode.Keys(), key)
+
+	if debug {
+		m.verifySearch(tnode.Keys(), key, i, ok)
+	}
+
+	if !ok {
+		tnode.InsertAt(i, key, value)
+
+		m.n++
+	}
+
+	return Iterator[Key, Value]{
+		node: tnode,
+		idx:  i,
+	}, ok, true
+}
+
+// findCached returns the cached leaf node if key falls in its range.
+// Otherwise, it returns nil.
+func (m *Map[Key, Value]) findCached(key Key) *leafNode[Key, Value] {
+	tnode := m.cache
+	if tnode == nil || tnode.n == 0 ||
+		m.compare(key, tnode.keys[0]) < 0 ||
+		m.compare(tnode.LastKey(), key) < 0 {
+		return nil
+	}
+
+	return tnode
+}
+
+// extendBack sets key to the last key of each internal node on the
+// path to m.back.  key must be greater than any keys in m.
+func (m *Map[Key, Value]) extendBack(key Key) {
+	node := m.root
+
+	for range m.height {
+		inode := node.(*internalNode[Key, Value])
+		inode.keys[inode.n-1] = key
+		node = inode.nodes[inode.n-1]
+	}
+}
+
+// verifySearch panics if i and found, which search returned for key
+// in keys, are inconsistent with compare.  It detects the compare
+// function that does not define a strict total order.
+func (m *Map[Key, Value]) verifySearch(
+	keys []Key, key Key, i int, found bool,
+) {
+	if i > 0 {
+		m.verifyLess(keys[i-1], key)
+	}
+
+	if i == len(keys) {
+		return
+	}
+
+	if !found {
+		m.verifyLess(key, keys[i])
+
+		return
+	}
+
+	if m.compare(keys[i], key) != 0 || m.compare(key, keys[i]) != 0 {
+		panic(fmt.Sprintf(
+			"treemap: compare is inconsistent: %v must equal to %v",
+			keys[i], key))
+	}
+}
+
+// verifyLess panics if compare does not consistently report that x
+// is less than y.
+func (m *Map[Key, Value]) verifyLess(x, y Key) {
+	if m.compare(x, y) >= 0 || m.compare(y, x) <= 0 {
+		panic(fmt.Sprintf(
+			"treemap: compare is inconsistent: %v must be less than %v",
+			x, y))
 	}
 }
 
@@ -101,9 +465,10 @@ func (m *Map[Key, Value]) splitRoot() {
 	rnode := m.root.Split(m)
 	lnode := m.root
 
-	root := &internalNode[Key, Value]{
-		n: 2,
-	}
+	m.splits++
+
+	root := m.newInternalNode()
+	root.n = 2
 
 	root.keys[0] = lnode.LastKey()
 	root.nodes[0] = lnode
@@ -112,6 +477,7 @@ func (m *Map[Key, Value]) splitRoot() {
 	root.nodes[1] = rnode
 
 	m.root = root
+	m.height++
 }
 
 // Insert inserts the given key-value pair.  If the key already
@@ -122,6 +488,109 @@ func (m *Map[Key, Value]) splitRoot() {
 func (m *Map[Key, Value]) Insert(
 	key Key, value Value,
 ) (Iterator[Key, Value], Value, bool) {
+	var oldValue Value
+
+	it, ok := m.tryInsert(key, value)
+	if ok {
+		oldValue = it.node.values[it.idx]
+		it.node.values[it.idx] = value
+	}
+
+	return it, oldValue, ok
+}
+
+// InsertNew inserts the given key-value pair only if key does not
+// exist.  It returns the Iterator that points to the inserted item
+// and true.  If key already exists, it returns the Iterator that
+// points to the existing item and false, leaving its value unchanged.
+func (m *Map[Key, Value]) InsertNew(
+	key Key, value Value,
+) (Iterator[Key, Value], bool) {
+	it, existed := m.tryInsert(key, value)
+
+	return it, !existed
+}
+
+// InsertNext inserts the given key-value pair as [Map.Insert] does,
+// and returns the Iterator that points to the item that follows the
+// inserted or updated item.
+func (m *Map[Key, Value]) InsertNext(
+	key Key, value Value,
+) Iterator[Key, Value] {
+	it, _, _ := m.Insert(key, value)
+
+	return it.Next()
+}
+
+// InsertWithNeighbors inserts the given key-value pair as
+// [Map.Insert] does.  It returns the Iterators that point to the item
+// before the inserted or updated item, that item, and the item after
+// it, and true if the existing value is replaced.  If the item is the
+// first one, prev is the Iterator that [Map.REnd] returns.  If it is
+// the last one, next is the Iterator that [Map.End] returns.  The
+// neighbours are found from the leaf node of the item without another
+// descent from the root.
+func (m *Map[Key, Value]) InsertWithNeighbors(
+	key Key, value Value,
+) (prev, cur, next Iterator[Key, Value], replaced bool) {
+	cur, _, replaced = m.Insert(key, value)
+
+	return cur.Prev(), cur, cur.Next(), replaced
+}
+
+// InsertStats is [Map.Insert] that additionally returns the number of
+// the node splits performed by the insertion.  It is intended for
+// instrumentation of the structural changes.
+func (m *Map[Key, Value]) InsertStats(
+	key Key, value Value,
+) (Iterator[Key, Value], Value, bool, int) {
+	splits := m.splits
+	it, oldValue, ok := m.Insert(key, value)
+
+	return it, oldValue, ok, m.splits - splits
+}
+
+// Upsert inserts the given key-value pair if key does not exist.  If
+// key already exists, it replaces the existing value with
+// combine(existing, value).  It returns the Iterator that points to
+// the inserted or updated item, and true if key already existed.
+func (m *Map[Key, Value]) Upsert(
+	key Key, value Value, combine func(existing, incoming Value) Value,
+) (Iterator[Key, Value], bool) {
+	it, ok := m.tryInsert(key, value)
+	if ok {
+		it.node.values[it.idx] = combine(it.node.values[it.idx], value)
+	}
+
+	return it, ok
+}
+
+// tryInsert inserts the given key-value pair if key does not exist.
+// It returns the Iterator that points to the item identified by key.
+// If key already exists, it returns true without changing its value.
+// Otherwise, it returns false.
+func (m *Map[Key, Value]) tryInsert(
+	key Key, value Value,
+) (Iterator[Key, Value], bool) {
+	if m.useLeafCache {
+		it, ok, hit := m.insertCached(key, value)
+		if hit {
+			return it, ok
+		}
+
+		it, ok = m.insert(key, value)
+		m.cache = it.node
+
+		return it, ok
+	}
+
+	return m.insert(key, value)
+}
+
+// insert is [Map.tryInsert] without the leaf cache.
+func (m *Map[Key, Value]) insert(
+	key Key, value Value,
+) (Iterator[Key, Value], bool) {
 	if m.root.IsFull() {
 		m.splitRoot()
 	}
@@ -130,13 +599,13 @@ func (m *Map[Key, Value]) Insert(
 
 	for {
 		if tnode, ok := node.(*leafNode[Key, Value]); ok {
-			var oldValue Value
-
 			i, ok := m.search(tnode.Keys(), key)
-			if ok {
-				oldValue = tnode.values[i]
-				tnode.values[i] = value
-			} else {
+
+			if debug {
+				m.verifySearch(tnode.Keys(), key, i, ok)
+			}
+
+			if !ok {
 				tnode.InsertAt(i, key, value)
 
 				m.n++
@@ -145,12 +614,17 @@ func (m *Map[Key, Value]) Insert(
 			return Iterator[Key, Value]{
 				node: tnode,
 				idx:  i,
-			}, oldValue, ok
+			}, ok
 		}
 
 		inode := node.(*internalNode[Key, Value])
 
-		i, _ := m.search(inode.Keys(), key)
+		i, ok := m.search(inode.Keys(), key)
+
+		if debug {
+			m.verifySearch(inode.Keys(), key, i, ok)
+		}
+
 		if i == inode.n {
 			for {
 				node = inode.nodes[inode.n-1]
@@ -175,12 +649,10 @@ func (m *Map[Key, Value]) Insert(
 
 			m.n++
 
-			var oldValue Value
-
 			return Iterator[Key, Value]{
 				node: tnode,
 				idx:  idx,
-			}, oldValue, false
+			}, false
 		}
 
 		descNode := inode.nodes[i]
@@ -197,122 +669,722 @@ func (m *Map[Key, Value]) Insert(
 	}
 }
 
-// Find returns value associated by key.  If such value exists, the
-// value and true are returned.  Otherwise, zero value and false are
-// returned.
-func (m *Map[Key, Value]) Find(key Key) (Value, bool) {
-	var z Value
+// Append inserts the given key-value pair if key is greater than any
+// keys in m.  It is faster than [Map.Insert] because it inserts the
+// item into the last leaf node without searching keys.  If key is not
+// greater than the largest key in m, it does nothing and returns
+// false.
+func (m *Map[Key, Value]) Append(key Key, value Value) bool {
+	tnode := m.back
+	if tnode.n > 0 && m.compare(key, tnode.LastKey()) <= 0 {
+		return false
+	}
 
-	node := m.root
+	if tnode.IsFull() {
+		m.insert(key, value)
 
-	for {
-		if tnode, ok := node.(*leafNode[Key, Value]); ok {
-			i, ok := m.search(tnode.Keys(), key)
-			if !ok {
-				return z, false
-			}
+		return true
+	}
 
-			return tnode.values[i], true
-		}
+	m.extendBack(key)
+	tnode.InsertAt(tnode.n, key, value)
 
-		inode := node.(*internalNode[Key, Value])
+	m.n++
 
-		i, _ := m.search(inode.KeysForFindAndRemove(), key)
-		node = inode.nodes[i]
-	}
+	return true
 }
 
-// LowerBound returns the Iterator that points to the item whose key
-// is the smallest key that is greater than or equal to key.  If all
-// stored keys are smaller than key, it returns the Iterator whose
-// [Iterator.End] returns true.
-func (m *Map[Key, Value]) LowerBound(key Key) Iterator[Key, Value] {
-	node := m.root
+// BulkAppend appends keys and the corresponding values to m.  keys
+// must be in the strictly increasing order, and the first key must be
+// greater than any keys in m.  It fills the last leaf node, packs the
+// rest of the items into new full leaf nodes, and attaches them to the
+// right edge of the tree.  It takes O(k + (k/b) log n) time where k is
+// the number of appended items, and b is the number of items in a
+// leaf node.  If the preconditions are not met, it returns an error,
+// and m is unchanged.  The error wraps [ErrOutOfOrder] or
+// [ErrDuplicateKey] if the keys violate the ordering.
+func (m *Map[Key, Value]) BulkAppend(keys []Key, values []Value) error {
+	if len(keys) != len(values) {
+		return errors.New("treemap: keys and values have different lengths")
+	}
 
-	for {
-		if tnode, ok := node.(*leafNode[Key, Value]); ok {
-			i, _ := m.search(tnode.Keys(), key)
-			if i == tnode.n && tnode.next != nil {
-				tnode = tnode.next
-				i = 0
-			}
+	if len(keys) == 0 {
+		return nil
+	}
 
-			return Iterator[Key, Value]{
-				node: tnode,
-				idx:  i,
-			}
+	if m.n > 0 {
+		if err := m.checkOrder(m.back.LastKey(), keys[0]); err != nil {
+			return fmt.Errorf("%w: keys[0] and the last key in m", err)
 		}
+	}
 
-		inode := node.(*internalNode[Key, Value])
+	for i := 1; i < len(keys); i++ {
+		if err := m.checkOrder(keys[i-1], keys[i]); err != nil {
+			return fmt.Errorf("%w: keys[%d] and keys[%d]", err, i-1, i)
+		}
+	}
 
-		i, _ := m.search(inode.KeysForFindAndRemove(), key)
-		node = inode.nodes[i]
+	i := 0
+
+	for ; i < len(keys) && !m.back.IsFull(); i++ {
+		m.Append(keys[i], values[i])
 	}
-}
 
-func (m *Map[Key, Value]) mergeNode(
-	node *internalNode[Key, Value], i int,
-) node[Key, Value] {
-	lnode := node.nodes[i]
-	rnode := node.nodes[i+1]
+	spine := m.rightSpine()
 
-	lnode.Merge(rnode, m)
+	for i < len(keys) {
+		tnode := m.newLeafNode()
+		tnode.n = copy(tnode.keys[:], keys[i:])
+		copy(tnode.values[:], values[i:i+tnode.n])
 
-	if m.root == node && node.n == 2 {
-		m.root = lnode
-	} else {
-		node.RemoveAt(i + 1)
-		node.keys[i] = lnode.LastKey()
+		i += tnode.n
+		m.n += tnode.n
+
+		tnode.prev = m.back
+		m.back.next = tnode
+		m.back = tnode
+
+		spine = m.appendChild(spine, 0, tnode)
+
+		for _, inode := range spine {
+			inode.keys[inode.n-1] = tnode.LastKey()
+		}
 	}
 
-	return lnode
+	// Only the nodes on the right edge can have too few items or
+	// children.  Their left siblings are full, and they are balanced
+	// from the top so that each parent has the left sibling to take
+	// from.
+	for l := len(spine) - 1; l >= 0; l-- {
+		inode := spine[l]
+		if inode.nodes[inode.n-1].Size() < m.minSize {
+			m.shiftRight(inode, inode.n-2)
+		}
+	}
+
+	return nil
 }
 
-func (m *Map[Key, Value]) shiftLeft(node *internalNode[Key, Value], i int) {
-	lnode := node.nodes[i-1]
-	rnode := node.nodes[i]
+// rightSpine returns the internal nodes on the path from the root to
+// m.back.  The i-th element is at height i+1, so that the last one is
+// the root.
+func (m *Map[Key, Value]) rightSpine() []*internalNode[Key, Value] {
+	spine := make([]*internalNode[Key, Value], m.height)
+	node := m.root
 
-	n := (lnode.Size()+rnode.Size()+1)/2 - lnode.Size()
+	for h := m.height; h > 0; h-- {
+		inode := node.(*internalNode[Key, Value])
+		spine[h-1] = inode
+		node = inode.nodes[inode.n-1]
+	}
 
-	lnode.ShiftLeft(rnode, n)
-	node.keys[i-1] = lnode.LastKey()
+	return spine
 }
 
-func (m *Map[Key, Value]) shiftRight(node *internalNode[Key, Value], i int) {
-	lnode := node.nodes[i]
-	rnode := node.nodes[i+1]
+// appendChild attaches child as the last child of spine[l], and
+// returns the updated spine.  If spine[l] is full, it starts a new
+// internal node that is attached to the parent in turn.  If child is
+// at the height of the root, it adds a new root.
+func (m *Map[Key, Value]) appendChild(
+	spine []*internalNode[Key, Value], l int, child node[Key, Value],
+) []*internalNode[Key, Value] {
+	if l == len(spine) {
+		root := m.newInternalNode()
+		root.nodes[0] = m.root
+		root.keys[0] = m.root.LastKey()
+		root.nodes[1] = child
+		root.keys[1] = child.LastKey()
+		root.n = 2
+
+		m.root = root
+		m.height++
+
+		return append(spine, root)
+	}
 
-	n := (lnode.Size()+rnode.Size()+1)/2 - rnode.Size()
+	inode := spine[l]
 
-	lnode.ShiftRight(rnode, n)
-	node.keys[i] = lnode.LastKey()
-}
+	if inode.IsFull() {
+		sibling := m.newInternalNode()
+		sibling.nodes[0] = child
+		sibling.keys[0] = child.LastKey()
+		sibling.n = 1
 
-// Remove removes the item identified by key.  If an item is removed,
-// it returns the removed value and true.  Otherwise, returns zero
-// value and false.
-func (m *Map[Key, Value]) Remove(key Key) (Value, bool) {
-	_, oldValue, ok := m.remove(key)
+		spine = m.appendChild(spine, l+1, sibling)
+		spine[l] = sibling
 
-	return oldValue, ok
+		return spine
+	}
+
+	inode.nodes[inode.n] = child
+	inode.keys[inode.n] = child.LastKey()
+	inode.n++
+
+	return spine
 }
 
-// RemoveIter removes the item pointed by it.  It returns the Iterator
-// that points to the item that follows the removed item.  The
-// provided it must not be invalidated, that means this function
-// always successfully remove the item.  The one exception is the case
-// where [Iterator.End] returns true.  In this case, this function
-// returns it without doing anything.
+// ReplaceAll replaces the contents of m with keys and the
+// corresponding values in place, so that all references to m observe
+// the new contents.  keys need not be sorted.  If a key appears more
+// than once, the value that appears last wins.  It builds the tree
+// bottom-up, which is much faster than inserting the items one by
+// one.  If keys and values have different lengths, it returns an
+// error, and m is unchanged.
+func (m *Map[Key, Value]) ReplaceAll(keys []Key, values []Value) error {
+	if len(keys) != len(values) {
+		return errors.New("treemap: keys and values have different lengths")
+	}
+
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+
+	if !m.isStrictlyIncreasing(keys) {
+		slices.SortStableFunc(order, func(x, y int) int {
+			return m.compare(keys[x], keys[y])
+		})
+	}
+
+	b := newBuilder(m)
+
+	for j, i := range order {
+		if j+1 < len(order) && m.compare(keys[i], keys[order[j+1]]) == 0 {
+			continue
+		}
+
+		b.add(keys[i], values[i])
+	}
+
+	b.finish()
+
+	return nil
+}
+
+// isStrictlyIncreasing returns true if keys are in the strictly
+// increasing order.
+func (m *Map[Key, Value]) isStrictlyIncreasing(keys []Key) bool {
+	for i := 1; i < len(keys); i++ {
+		if m.compare(keys[i-1], keys[i]) >= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkOrder returns [ErrDuplicateKey] if x equals to y, or
+// [ErrOutOfOrder] if x is greater than y.  Otherwise, it returns nil.
+func (m *Map[Key, Value]) checkOrder(x, y Key) error {
+	switch c := m.compare(x, y); {
+	case c == 0:
+		return ErrDuplicateKey
+	case c > 0:
+		return ErrOutOfOrder
+	}
+
+	return nil
+}
+
+// Find returns value associated by key.  If such value exists, the
+// value and true are returned.  Otherwise, zero value and false are
+// returned.
+func (m *Map[Key, Value]) Find(key Key) (Value, bool) {
+	var z Value
+
+	tnode := m.lookupLeaf(key)
+
+	i, ok := m.search(tnode.Keys(), key)
+	if !ok {
+		return z, false
+	}
+
+	return tnode.values[i], true
+}
+
+// GetOr returns the value associated by key if it exists.  Otherwise,
+// it returns def.
+func (m *Map[Key, Value]) GetOr(key Key, def Value) Value {
+	if v, ok := m.Find(key); ok {
+		return v
+	}
+
+	return def
+}
+
+// FindIter returns the Iterator that points to the item identified by
+// key, and true.  If there is no such item, it returns the Iterator
+// whose [Iterator.End] returns true, and false.
+func (m *Map[Key, Value]) FindIter(key Key) (Iterator[Key, Value], bool) {
+	it, ok := m.LocateInsert(key)
+	if !ok {
+		return m.End(), false
+	}
+
+	return it, true
+}
+
+// GetMany returns the values associated by keys and whether they are
+// found.  The returned slices are parallel to keys.  If keys are
+// sorted, it looks them up in that order and descends the tree only
+// when a key is beyond the current leaf node, so that the keys close
+// to each other share the descent.  Otherwise, it does the same with
+// the sorted copy of the indices of keys.
+func (m *Map[Key, Value]) GetMany(keys []Key) ([]Value, []bool) {
+	values := make([]Value, len(keys))
+	found := make([]bool, len(keys))
+
+	m.findMany(keys, func(i int, tnode *leafNode[Key, Value], j int) {
+		values[i] = tnode.values[j]
+		found[i] = true
+	})
+
+	return values, found
+}
+
+// ContainsMany returns whether keys are in m.  The returned slice is
+// parallel to keys.  It looks up keys in the same way as
+// [Map.GetMany].
+func (m *Map[Key, Value]) ContainsMany(keys []Key) []bool {
+	found := make([]bool, len(keys))
+
+	m.findMany(keys, func(i int, _ *leafNode[Key, Value], _ int) {
+		found[i] = true
+	})
+
+	return found
+}
+
+// findMany looks up keys as described in [Map.GetMany], and calls fn
+// with the index of the key in keys, the leaf node, and the index of
+// the key in the leaf node for each key found.
+func (m *Map[Key, Value]) findMany(
+	keys []Key, fn func(i int, tnode *leafNode[Key, Value], j int),
+) {
+	if m.n == 0 {
+		return
+	}
+
+	var order []int
+
+	if !slices.IsSortedFunc(keys, m.compare) {
+		order = make([]int, len(keys))
+		for i := range order {
+			order[i] = i
+		}
+
+		slices.SortFunc(order, func(x, y int) int {
+			return m.compare(keys[x], keys[y])
+		})
+	}
+
+	var tnode *leafNode[Key, Value]
+
+	for j := range keys {
+		i := j
+		if order != nil {
+			i = order[j]
+		}
+
+		key := keys[i]
+
+		if tnode == nil || m.compare(key, tnode.LastKey()) > 0 {
+			tnode = m.findLeaf(key)
+		}
+
+		if k, ok := m.search(tnode.Keys(), key); ok {
+			fn(i, tnode, k)
+		}
+	}
+}
+
+// Replace replaces the value associated by key with value only if
+// key exists.  If the value is replaced, it returns the old value and
+// true.  Otherwise, it returns zero value and false, and m is
+// unchanged.
+func (m *Map[Key, Value]) Replace(key Key, value Value) (Value, bool) {
+	var oldValue Value
+
+	tnode := m.lookupLeaf(key)
+
+	i, ok := m.search(tnode.Keys(), key)
+	if !ok {
+		return oldValue, false
+	}
+
+	oldValue = tnode.values[i]
+	tnode.values[i] = value
+
+	return oldValue, true
+}
+
+// lookupLeaf is [Map.findLeaf] that consults the leaf cache if it is
+// enabled.  It does not update the cache.
+func (m *Map[Key, Value]) lookupLeaf(key Key) *leafNode[Key, Value] {
+	if m.useLeafCache {
+		if tnode := m.findCached(key); tnode != nil {
+			return tnode
+		}
+	}
+
+	return m.findLeaf(key)
+}
+
+// findLeaf returns the leaf node that key belongs to.
+func (m *Map[Key, Value]) findLeaf(key Key) *leafNode[Key, Value] {
+	node := m.root
+
+	for {
+		if tnode, ok := node.(*leafNode[Key, Value]); ok {
+			return tnode
+		}
+
+		inode := node.(*internalNode[Key, Value])
+
+		i, _ := m.search(inode.KeysForFindAndRemove(), key)
+		node = inode.nodes[i]
+	}
+}
+
+// LowerBound returns the Iterator that points to the item whose key
+// is the smallest key that is greater than or equal to key.  If all
+// stored keys are smaller than key, it returns the Iterator whose
+// [Iterator.End] returns true.
+func (m *Map[Key, Value]) LowerBound(key Key) Iterator[Key, Value] {
+	it, _ := m.LocateInsert(key)
+
+	return it
+}
+
+// LowerBoundFunc returns the Iterator that points to the first item
+// whose key k satisfies match(k) >= 0, like [Map.LowerBound].  match
+// returns a negative number if k is less than the target, zero if k
+// matches the target, and a positive number if k is greater than the
+// target, in the same way as compare(k, target).  It allows searching
+// by a field derived from the key without constructing a full key.
+// match must be consistent with the order of keys in m; otherwise,
+// the result is undefined.  If no key satisfies it, it returns the
+// Iterator whose [Iterator.End] returns true.
+func (m *Map[Key, Value]) LowerBoundFunc(
+	match func(Key) int,
+) Iterator[Key, Value] {
+	node := m.root
+
+	for range m.height {
+		inode := node.(*internalNode[Key, Value])
+		node = inode.nodes[searchFunc(inode.KeysForFindAndRemove(), match)]
+	}
+
+	tnode := node.(*leafNode[Key, Value])
+
+	i := searchFunc(tnode.Keys(), match)
+	if i == tnode.n && tnode.next != nil {
+		tnode = tnode.next
+		i = 0
+	}
+
+	return Iterator[Key, Value]{
+		node: tnode,
+		idx:  i,
+	}
+}
+
+// searchFunc returns the smallest index i in keys such that
+// match(keys[i]) >= 0, or len(keys) if there is no such index.
+func searchFunc[Key any](keys []Key, match func(Key) int) int {
+	return sort.Search(len(keys), func(i int) bool {
+		return match(keys[i]) >= 0
+	})
+}
+
+// lowerBoundScanLeaves is the maximum number of leaf nodes that
+// [Map.LowerBoundFrom] examines before it falls back to
+// [Map.LowerBound].
+const lowerBoundScanLeaves = 4
+
+// LowerBoundFrom returns the same Iterator as [Map.LowerBound], using
+// hint as the starting point of the search.  It is intended for the
+// repeated lookups with the increasing keys, such as merge-join,
+// where hint is the Iterator returned by the previous lookup.  If key
+// is not less than the key pointed by hint, it scans forward along
+// the leaf nodes from hint, and if key is not found within a few leaf
+// nodes, it falls back to [Map.LowerBound].  If key is less than the
+// key pointed by hint, or hint does not point to an item, it is
+// equivalent to [Map.LowerBound].  hint must not be invalidated.
+func (m *Map[Key, Value]) LowerBoundFrom(
+	hint Iterator[Key, Value], key Key,
+) Iterator[Key, Value] {
+	if hint.REnd() || hint.End() || m.compare(key, hint.Key()) < 0 {
+		return m.LowerBound(key)
+	}
+
+	tnode, idx := hint.node, hint.idx
+
+	for range lowerBoundScanLeaves {
+		if m.compare(key, tnode.LastKey()) <= 0 {
+			i, _ := m.search(tnode.keys[idx:tnode.n], key)
+
+			return Iterator[Key, Value]{
+				node: tnode,
+				idx:  idx + i,
+			}
+		}
+
+		if tnode.next == nil {
+			return m.End()
+		}
+
+		tnode, idx = tnode.next, 0
+	}
+
+	return m.LowerBound(key)
+}
+
+// LocateInsert returns the Iterator that points to the position where
+// key would be inserted, that is the Iterator that [Map.LowerBound]
+// returns, and true if key is already in m.  It does not modify m.
+func (m *Map[Key, Value]) LocateInsert(key Key) (Iterator[Key, Value], bool) {
+	tnode := m.findLeaf(key)
+
+	i, found := m.search(tnode.Keys(), key)
+	if i == tnode.n && tnode.next != nil {
+		tnode = tnode.next
+		i = 0
+	}
+
+	return Iterator[Key, Value]{
+		node: tnode,
+		idx:  i,
+	}, found
+}
+
+// floor returns the Iterator that points to the item whose key is
+// the largest key that is less than or equal to key, and true.  If
+// all stored keys are greater than key, it returns false.
+func (m *Map[Key, Value]) floor(key Key) (Iterator[Key, Value], bool) {
+	it := m.LowerBound(key)
+	if !it.End() && m.compare(it.Key(), key) == 0 {
+		return it, true
+	}
+
+	if it.Begin() {
+		return it, false
+	}
+
+	return it.Prev(), true
+}
+
+// FloorValue returns the largest key that is less than or equal to
+// key, its value, and true.  If all stored keys are greater than key,
+// it returns zero values and false.
+func (m *Map[Key, Value]) FloorValue(key Key) (Key, Value, bool) {
+	it, ok := m.floor(key)
+	if !ok {
+		var (
+			k Key
+			v Value
+		)
+
+		return k, v, false
+	}
+
+	return it.Key(), it.Value(), true
+}
+
+// CeilingValue returns the smallest key that is greater than or
+// equal to key, its value, and true.  If all stored keys are smaller
+// than key, it returns zero values and false.
+func (m *Map[Key, Value]) CeilingValue(key Key) (Key, Value, bool) {
+	it := m.LowerBound(key)
+	if it.End() {
+		var (
+			k Key
+			v Value
+		)
+
+		return k, v, false
+	}
+
+	return it.Key(), it.Value(), true
+}
+
+// FloorEntry returns the item whose key is the largest key that is
+// less than or equal to key, and true.  If all stored keys are greater
+// than key, it returns zero value and false.
+func (m *Map[Key, Value]) FloorEntry(key Key) (Entry[Key, Value], bool) {
+	it, ok := m.floor(key)
+	if !ok {
+		return Entry[Key, Value]{}, false
+	}
+
+	return Entry[Key, Value]{Key: it.Key(), Value: it.Value()}, true
+}
+
+// CeilingEntry returns the item whose key is the smallest key that is
+// greater than or equal to key, and true.  If all stored keys are
+// smaller than key, it returns zero value and false.
+func (m *Map[Key, Value]) CeilingEntry(
+	key Key,
+) (Entry[Key, Value], bool) {
+	it := m.LowerBound(key)
+	if it.End() {
+		return Entry[Key, Value]{}, false
+	}
+
+	return Entry[Key, Value]{Key: it.Key(), Value: it.Value()}, true
+}
+
+// NextFree returns the first key that is not in m among start,
+// inc(start), inc(inc(start)), and so forth, and true.  inc must
+// return a key greater than its argument unless the key space is
+// exhausted.  If inc returns a key that is not greater than its
+// argument, it returns zero value and false.  It walks the items from
+// the lower bound of start, so it finds the first gap in the run of
+// the consecutive keys in O(log n + k) time where k is the length of
+// the run.
+func (m *Map[Key, Value]) NextFree(
+	start Key, inc func(Key) Key,
+) (Key, bool) {
+	key := start
+
+	for it := m.LowerBound(start); ; {
+		for !it.End() && m.compare(it.Key(), key) < 0 {
+			it = it.Next()
+		}
+
+		if it.End() || m.compare(it.Key(), key) != 0 {
+			return key, true
+		}
+
+		next := inc(key)
+		if m.compare(next, key) <= 0 {
+			var k Key
+
+			return k, false
+		}
+
+		key = next
+	}
+}
+
+// Nearest returns the key in m that is the closest to key under
+// dist, its value, and true.  dist returns the non-negative distance
+// between two keys.  Only the floor and the ceiling of key are
+// considered, and the floor is preferred if their distances are the
+// same.  If key is in m, it is returned regardless of dist.  If m is
+// empty, it returns zero values and false.
+func Nearest[Key cmp.Ordered, Value any](
+	m *Map[Key, Value], key Key, dist func(a, b Key) int,
+) (Key, Value, bool) {
+	it := m.LowerBound(key)
+
+	if it.Begin() {
+		if it.End() {
+			var (
+				k Key
+				v Value
+			)
+
+			return k, v, false
+		}
+
+		return it.Key(), it.Value(), true
+	}
+
+	if !it.End() && m.compare(it.Key(), key) == 0 {
+		return it.Key(), it.Value(), true
+	}
+
+	if it.End() || dist(it.Prev().Key(), key) <= dist(it.Key(), key) {
+		it = it.Prev()
+	}
+
+	return it.Key(), it.Value(), true
+}
+
+func (m *Map[Key, Value]) mergeNode(
+	node *internalNode[Key, Value], i int,
+) node[Key, Value] {
+	lnode := node.nodes[i]
+	rnode := node.nodes[i+1]
+
+	lnode.Merge(rnode, m)
+	// Merge drops rnode from the tree.  Splitting and shifting do not
+	// invalidate the cache because its range is derived from the keys
+	// that the cached leaf node contains.
+	m.cache = nil
+	m.freeNode(rnode)
+
+	if m.root == node && node.n == 2 {
+		m.root = lnode
+		m.height--
+		m.freeNode(node)
+	} else {
+		node.RemoveAt(i + 1)
+		node.keys[i] = lnode.LastKey()
+	}
+
+	return lnode
+}
+
+func (m *Map[Key, Value]) shiftLeft(node *internalNode[Key, Value], i int) {
+	lnode := node.nodes[i-1]
+	rnode := node.nodes[i]
+
+	n := (lnode.Size()+rnode.Size()+1)/2 - lnode.Size()
+
+	lnode.ShiftLeft(rnode, n)
+	node.keys[i-1] = lnode.LastKey()
+}
+
+func (m *Map[Key, Value]) shiftRight(node *internalNode[Key, Value], i int) {
+	lnode := node.nodes[i]
+	rnode := node.nodes[i+1]
+
+	n := (lnode.Size()+rnode.Size()+1)/2 - rnode.Size()
+
+	lnode.ShiftRight(rnode, n)
+	node.keys[i] = lnode.LastKey()
+}
+
+// Remove removes the item identified by key.  If an item is removed,
+// it returns the removed value and true.  Otherwise, returns zero
+// value and false.
+func (m *Map[Key, Value]) Remove(key Key) (Value, bool) {
+	_, oldValue, ok := m.remove(key)
+
+	return oldValue, ok
+}
+
+// RemoveKey removes the item identified by key.  If an item is
+// removed, it returns the removed value, the Iterator that points to
+// the item that follows the removed item, and true.  Otherwise, it
+// returns zero value, the Iterator whose [Iterator.End] returns true,
+// and false.
+func (m *Map[Key, Value]) RemoveKey(
+	key Key,
+) (Value, Iterator[Key, Value], bool) {
+	it, oldValue, ok := m.remove(key)
+
+	return oldValue, it, ok
+}
+
+// RemoveIter removes the item pointed by it.  It returns the Iterator
+// that points to the item that follows the removed item.  The
+// provided it must not be invalidated, that means this function
+// always successfully remove the item.  The exceptions are the cases
+// where [Iterator.End] or [Iterator.REnd] returns true.  In these
+// cases, this function returns it without doing anything.
 func (m *Map[Key, Value]) RemoveIter(
 	it Iterator[Key, Value],
 ) Iterator[Key, Value] {
-	if it.End() {
+	if it.End() || it.REnd() {
 		return it
 	}
 
 	tnode := it.node
 
-	if tnode != m.root && tnode.n == minNodes {
+	if tnode != m.root && tnode.n <= m.minSize {
 		it, _, _ := m.remove(it.Key())
 		return it
 	}
@@ -338,8 +1410,8 @@ func (m *Map[Key, Value]) remove(key Key) (Iterator[Key, Value], Value, bool) {
 
 	if inode, ok := node.(*internalNode[Key, Value]); ok {
 		if inode.n == 2 &&
-			inode.nodes[0].Size() == minNodes &&
-			inode.nodes[1].Size() == minNodes {
+			inode.nodes[0].Size() <= m.minSize &&
+			inode.nodes[1].Size() <= m.minSize {
 			node = m.mergeNode(inode, 0)
 		}
 	}
@@ -375,12 +1447,12 @@ func (m *Map[Key, Value]) remove(key Key) (Iterator[Key, Value], Value, bool) {
 		i, _ := m.search(inode.KeysForFindAndRemove(), key)
 		descNode := inode.nodes[i]
 
-		if descNode.Size() > minNodes {
+		if descNode.Size() > m.minSize {
 			node = descNode
 			continue
 		}
 
-		if i+1 < inode.n && inode.nodes[i+1].Size() > minNodes {
+		if i+1 < inode.n && inode.nodes[i+1].Size() > m.minSize {
 			m.shiftLeft(inode, i+1)
 
 			node = descNode
@@ -388,7 +1460,7 @@ func (m *Map[Key, Value]) remove(key Key) (Iterator[Key, Value], Value, bool) {
 			continue
 		}
 
-		if i > 0 && inode.nodes[i-1].Size() > minNodes {
+		if i > 0 && inode.nodes[i-1].Size() > m.minSize {
 			m.shiftRight(inode, i-1)
 
 			node = descNode
@@ -405,27 +1477,331 @@ func (m *Map[Key, Value]) remove(key Key) (Iterator[Key, Value], Value, bool) {
 	}
 }
 
-// Begin returns the Iterator that points to the first item.
-func (m *Map[Key, Value]) Begin() Iterator[Key, Value] {
-	return Iterator[Key, Value]{
-		node: m.front,
-	}
-}
+// DrainRange returns an iterator that removes the items whose keys
+// are in [lo, hi) from m and yields them in the sorted order.  Each
+// item is removed before it is yielded, and the items after the
+// iteration stops remain in m.  m must not be modified during
+// iteration except by the iterator itself.  If lo is not less than
+// hi, the iterator yields nothing.
+func (m *Map[Key, Value]) DrainRange(lo, hi Key) iter.Seq2[Key, Value] {
+	return func(yield func(Key, Value) bool) {
+		if m.compare(lo, hi) >= 0 {
+			return
+		}
 
-// End returns the Iterator that points to the one beyond the last
-// item.
-func (m *Map[Key, Value]) End() Iterator[Key, Value] {
-	return Iterator[Key, Value]{
-		node: m.back,
-		idx:  m.back.n,
+		it := m.LowerBound(lo)
+
+		for !it.End() && m.compare(it.Key(), hi) < 0 {
+			k, v := it.KeyValue()
+			it = m.RemoveIter(it)
+
+			if !yield(k, v) {
+				return
+			}
+		}
 	}
 }
 
+// RemoveMin removes the item with the smallest key.  It returns true
+// if an item is removed, or false if m is empty.
+func (m *Map[Key, Value]) RemoveMin() bool {
+	if m.n == 0 {
+		return false
+	}
+
+	m.RemoveIter(m.Begin())
+
+	return true
+}
+
+// RemoveMax removes the item with the largest key.  It returns true
+// if an item is removed, or false if m is empty.
+func (m *Map[Key, Value]) RemoveMax() bool {
+	if m.n == 0 {
+		return false
+	}
+
+	m.RemoveIter(m.End().Prev())
+
+	return true
+}
+
+// ClearRange removes the items whose keys are in [lo, hi) from m, and
+// returns m.  If lo is not less than hi, it does nothing.
+func (m *Map[Key, Value]) ClearRange(lo, hi Key) *Map[Key, Value] {
+	if m.compare(lo, hi) >= 0 {
+		return m
+	}
+
+	it := m.LowerBound(lo)
+
+	for !it.End() && m.compare(it.Key(), hi) < 0 {
+		it = m.RemoveIter(it)
+	}
+
+	return m
+}
+
+// Prune calls fn for each item in m in the sorted order, and removes
+// the item if fn returns true.  It returns the number of removed
+// items.  fn must not modify m.  It is safe to use Prune instead of
+// removing the items while iterating over m, which invalidates the
+// iterator.
+func (m *Map[Key, Value]) Prune(fn func(key Key, value Value) bool) int {
+	n := m.n
+
+	for it := m.Begin(); !it.End(); {
+		if fn(it.KeyValue()) {
+			it = m.RemoveIter(it)
+		} else {
+			it = it.Next()
+		}
+	}
+
+	return n - m.n
+}
+
+// UpdateIf calls pred for each item in m in the sorted order, and
+// replaces the value of the item with fn(value) if pred returns true.
+// It returns the number of the updated items.  Because only the values
+// are changed, it walks the leaf nodes and writes the values in place
+// without restructuring the tree.  pred and fn must not modify m.
+func (m *Map[Key, Value]) UpdateIf(
+	pred func(key Key, value Value) bool, fn func(value Value) Value,
+) int {
+	n := 0
+
+	for tnode := m.front; tnode != nil; tnode = tnode.next {
+		for i := range tnode.n {
+			if pred(tnode.keys[i], tnode.values[i]) {
+				tnode.values[i] = fn(tnode.values[i])
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+// Begin returns the Iterator that points to the first item.
+func (m *Map[Key, Value]) Begin() Iterator[Key, Value] {
+	return Iterator[Key, Value]{
+		node: m.front,
+	}
+}
+
+// End returns the Iterator that points to the one beyond the last
+// item.
+func (m *Map[Key, Value]) End() Iterator[Key, Value] {
+	return Iterator[Key, Value]{
+		node: m.back,
+		idx:  m.back.n,
+	}
+}
+
+// RBegin returns the Iterator that points to the last item.  If m is
+// empty, it returns the Iterator that [Map.REnd] returns.  Together
+// with [Map.REnd] and [Iterator.Prev], it iterates items in the
+// reverse order.
+func (m *Map[Key, Value]) RBegin() Iterator[Key, Value] {
+	return Iterator[Key, Value]{
+		node: m.back,
+		idx:  m.back.n - 1,
+	}
+}
+
+// REnd returns the Iterator that points to the one before the first
+// item.  The returned Iterator must not be dereferenced.
+func (m *Map[Key, Value]) REnd() Iterator[Key, Value] {
+	return Iterator[Key, Value]{
+		node: m.front,
+		idx:  -1,
+	}
+}
+
+// FirstEntry returns the Iterator that points to the first item and
+// true.  If m is empty, it returns the Iterator that equals to
+// [Map.End] and false.
+func (m *Map[Key, Value]) FirstEntry() (Iterator[Key, Value], bool) {
+	return m.Begin(), m.n > 0
+}
+
+// LastEntry returns the Iterator that points to the last item and
+// true.  If m is empty, it returns the Iterator that equals to
+// [Map.End] and false.
+func (m *Map[Key, Value]) LastEntry() (Iterator[Key, Value], bool) {
+	if m.n == 0 {
+		return m.End(), false
+	}
+
+	return m.RBegin(), true
+}
+
+// PeekFirst returns the first item and true.  If m is empty, it
+// returns zero value and false.
+func (m *Map[Key, Value]) PeekFirst() (Entry[Key, Value], bool) {
+	it, ok := m.FirstEntry()
+	if !ok {
+		return Entry[Key, Value]{}, false
+	}
+
+	return Entry[Key, Value]{Key: it.Key(), Value: it.Value()}, true
+}
+
+// PeekLast returns the last item and true.  If m is empty, it returns
+// zero value and false.
+func (m *Map[Key, Value]) PeekLast() (Entry[Key, Value], bool) {
+	it, ok := m.LastEntry()
+	if !ok {
+		return Entry[Key, Value]{}, false
+	}
+
+	return Entry[Key, Value]{Key: it.Key(), Value: it.Value()}, true
+}
+
+// Empty returns true if m has no items.
+func (m *Map[Key, Value]) Empty() bool {
+	return m.n == 0
+}
+
+// Comparator returns the function that m uses to compare keys.  For
+// the Map created by [New], it is [cmp.Compare].
+func (m *Map[Key, Value]) Comparator() Compare[Key] {
+	return m.compare
+}
+
 // Len returns the number of items that m contains.
 func (m *Map[Key, Value]) Len() int {
 	return m.n
 }
 
+// CountFrom returns the number of items from it to the end,
+// including the item pointed by it.  If [Iterator.REnd] returns true,
+// it returns 0.  It walks the leaf nodes, so it
+// takes time proportional to the number of items divided by the
+// number of items in a leaf node.
+func (m *Map[Key, Value]) CountFrom(it Iterator[Key, Value]) int {
+	return it.remaining()
+}
+
+// at returns the Iterator that points to the i-th item in the sorted
+// order, and true.  If i is out of range, it returns false.  It walks
+// the leaf nodes from the nearer end, and skips them as a whole.
+func (m *Map[Key, Value]) at(i int) (Iterator[Key, Value], bool) {
+	if i < 0 || i >= m.n {
+		return Iterator[Key, Value]{}, false
+	}
+
+	if i < m.n/2 {
+		tnode := m.front
+		for ; i >= tnode.n; tnode = tnode.next {
+			i -= tnode.n
+		}
+
+		return Iterator[Key, Value]{node: tnode, idx: i}, true
+	}
+
+	i = m.n - 1 - i
+
+	tnode := m.back
+	for ; i >= tnode.n; tnode = tnode.prev {
+		i -= tnode.n
+	}
+
+	return Iterator[Key, Value]{node: tnode, idx: tnode.n - 1 - i}, true
+}
+
+// IndexOf returns the 0-based index of key in the sorted order of the
+// keys in m, and true.  If key is not in m, it returns 0 and false.
+// It counts the items in the leaf nodes that follow the one
+// containing key, which takes O(n/b) time where b is the number of
+// items in a leaf node.
+func (m *Map[Key, Value]) IndexOf(key Key) (int, bool) {
+	it, ok := m.FindIter(key)
+	if !ok {
+		return 0, false
+	}
+
+	return m.n - it.remaining(), true
+}
+
+// KeyAt returns the i-th smallest key in m, counting from 0, and
+// true.  If i is out of range, it returns zero value and false.  It
+// takes O(min(i, n-i)/b) time where b is the number of items in a
+// leaf node.
+func (m *Map[Key, Value]) KeyAt(i int) (Key, bool) {
+	it, ok := m.at(i)
+	if !ok {
+		var k Key
+
+		return k, false
+	}
+
+	return it.Key(), true
+}
+
+// SetValueAt replaces the value of the item with the i-th smallest
+// key in m, counting from 0, with value, and returns true.  If i is
+// out of range, it returns false.  Its time complexity is the same as
+// [Map.KeyAt].
+func (m *Map[Key, Value]) SetValueAt(i int, value Value) bool {
+	it, ok := m.at(i)
+	if !ok {
+		return false
+	}
+
+	it.SetValue(value)
+
+	return true
+}
+
+// Sample returns an iterator over k items chosen at random from m
+// without replacement.  Every k-subset of the items is chosen with
+// equal probability, and the chosen items are yielded in the sorted
+// order.  If k is greater than the number of items, all items are
+// yielded.  A new sample is drawn from rng each time the iterator is
+// started.  It draws k distinct indices, and then walks the leaf
+// nodes, skipping them as a whole.  It takes O(k log k + n/b) time
+// where b is the number of items in a leaf node.
+func (m *Map[Key, Value]) Sample(
+	k int, rng *rand.Rand,
+) iter.Seq2[Key, Value] {
+	return func(yield func(Key, Value) bool) {
+		n := m.n
+		k := min(k, n)
+
+		if k <= 0 {
+			return
+		}
+
+		// Robert Floyd's algorithm picks k distinct indices in [0, n)
+		// uniformly with k random numbers.
+		chosen := make(map[int]struct{}, k)
+
+		for j := n - k; j < n; j++ {
+			i := rng.IntN(j + 1)
+			if _, ok := chosen[i]; ok {
+				i = j
+			}
+
+			chosen[i] = struct{}{}
+		}
+
+		idxs := slices.Sorted(maps.Keys(chosen))
+		tnode, base := m.front, 0
+
+		for _, i := range idxs {
+			for ; i >= base+tnode.n; tnode = tnode.next {
+				base += tnode.n
+			}
+
+			if !yield(tnode.keys[i-base], tnode.values[i-base]) {
+				return
+			}
+		}
+	}
+}
+
 // Keys returns an iterator over keys in m in the sorted order.
 func (m *Map[Key, Value]) Keys() iter.Seq[Key] {
 	return func(yield func(Key) bool) {
@@ -437,6 +1813,43 @@ func (m *Map[Key, Value]) Keys() iter.Seq[Key] {
 	}
 }
 
+// Items returns an iterator over the items in m as [Entry] in the
+// sorted order.
+func (m *Map[Key, Value]) Items() iter.Seq[Entry[Key, Value]] {
+	return func(yield func(Entry[Key, Value]) bool) {
+		for it := m.Begin(); !it.End(); it = it.Next() {
+			if !yield(Entry[Key, Value]{Key: it.Key(), Value: it.Value()}) {
+				return
+			}
+		}
+	}
+}
+
+// AppendKeys appends keys in m to dst in the sorted order and returns
+// the extended slice.  It grows dst at most once.
+func (m *Map[Key, Value]) AppendKeys(dst []Key) []Key {
+	dst = slices.Grow(dst, m.n)
+
+	for tnode := m.front; tnode != nil; tnode = tnode.next {
+		dst = append(dst, tnode.keys[:tnode.n]...)
+	}
+
+	return dst
+}
+
+// AppendValues appends values in m to dst in the sorted order of the
+// corresponding keys and returns the extended slice.  It grows dst at
+// most once.
+func (m *Map[Key, Value]) AppendValues(dst []Value) []Value {
+	dst = slices.Grow(dst, m.n)
+
+	for tnode := m.front; tnode != nil; tnode = tnode.next {
+		dst = append(dst, tnode.values[:tnode.n]...)
+	}
+
+	return dst
+}
+
 // Values returns an iterator over values in m in the sorted order of
 // the corresponding keys.
 func (m *Map[Key, Value]) Values() iter.Seq[Value] {
@@ -449,25 +1862,584 @@ func (m *Map[Key, Value]) Values() iter.Seq[Value] {
 	}
 }
 
-// String returns the string representation of m.
+// Range returns an iterator over the items whose keys are in [lo,
+// hi) in the sorted order.  If lo is not less than hi, the iterator
+// yields nothing.
+func (m *Map[Key, Value]) Range(lo, hi Key) iter.Seq2[Key, Value] {
+	return func(yield func(Key, Value) bool) {
+		if m.compare(lo, hi) >= 0 {
+			return
+		}
+
+		for it := m.LowerBound(lo); !it.End(); it = it.Next() {
+			if m.compare(it.Key(), hi) >= 0 || !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// RangeInclusive returns an iterator over the items whose keys are in
+// [lo, hi] in the sorted order.  If lo is greater than hi, the
+// iterator yields nothing.
+func (m *Map[Key, Value]) RangeInclusive(
+	lo, hi Key,
+) iter.Seq2[Key, Value] {
+	return func(yield func(Key, Value) bool) {
+		if m.compare(lo, hi) > 0 {
+			return
+		}
+
+		for it := m.LowerBound(lo); !it.End(); it = it.Next() {
+			if m.compare(it.Key(), hi) > 0 || !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// RangeReduce folds the items in m whose keys are in [lo, hi) in the
+// sorted order.  It calls fn with the accumulated value, starting
+// from init, and each item, and returns the final accumulated value.
+// If lo is not less than hi, it returns init.  It is a function rather
+// than a method of Map because methods cannot have type parameters.
+func RangeReduce[Key, Value, Acc any](
+	m *Map[Key, Value], lo, hi Key, init Acc,
+	fn func(acc Acc, key Key, value Value) Acc,
+) Acc {
+	if m.compare(lo, hi) >= 0 {
+		return init
+	}
+
+	acc := init
+
+	for it := m.LowerBound(lo); !it.End(); it = it.Next() {
+		if m.compare(it.Key(), hi) >= 0 {
+			break
+		}
+
+		acc = fn(acc, it.Key(), it.Value())
+	}
+
+	return acc
+}
+
+// GroupBy returns an iterator over the groups of the consecutive
+// items in m whose keys have the same bucket(key).  It yields the
+// bucket and an iterator over the items in the group in the sorted
+// order.  If bucket is monotonic with respect to the order of keys,
+// such as truncating a timestamp to a time window, each bucket forms
+// exactly one group.  Otherwise, a bucket may appear in more than one
+// group.  bucket is called once for each item.  The iterator of a
+// group is valid only until the next group is yielded.  It is a
+// function rather than a method of Map because methods cannot have
+// type parameters.
+func GroupBy[Key, Value any, B comparable](
+	m *Map[Key, Value], bucket func(Key) B,
+) iter.Seq2[B, iter.Seq2[Key, Value]] {
+	return func(yield func(B, iter.Seq2[Key, Value]) bool) {
+		it := m.Begin()
+		if it.End() {
+			return
+		}
+
+		b := bucket(it.Key())
+
+		for !it.End() {
+			start := it
+			n := 1
+
+			var next B
+
+			for it = it.Next(); !it.End(); it = it.Next() {
+				next = bucket(it.Key())
+				if next != b {
+					break
+				}
+
+				n++
+			}
+
+			if !yield(b, start.Take(n)) {
+				return
+			}
+
+			b = next
+		}
+	}
+}
+
+// RangeIter returns the pair of Iterators that delimits the items
+// whose keys are in [lo, hi).  begin points to the first item in the
+// range, and end points to the one beyond the last item in the range.
+// If the range is empty, begin and end are equal.  It is the Iterator
+// form of [Map.Range].
+func (m *Map[Key, Value]) RangeIter(
+	lo, hi Key,
+) (begin, end Iterator[Key, Value]) {
+	begin = m.LowerBound(lo)
+
+	if m.compare(lo, hi) >= 0 {
+		return begin, begin
+	}
+
+	return begin, m.LowerBound(hi)
+}
+
+// CopyRange returns a new Map that contains the copies of the items
+// in m whose keys are in [lo, hi).  The new Map compares keys in the
+// same way as m.  The items are bulk-loaded into the new Map, which
+// is much faster than inserting them one by one.  If lo is not less
+// than hi, it returns an empty Map.
+func (m *Map[Key, Value]) CopyRange(lo, hi Key) *Map[Key, Value] {
+	r := m.newEmpty()
+	b := newBuilder(r)
+
+	for k, v := range m.Range(lo, hi) {
+		b.add(k, v)
+	}
+
+	b.finish()
+
+	return r
+}
+
+// CutRange removes the items whose keys are in [lo, hi) from m, and
+// returns a new Map that contains them.  The new Map compares keys in
+// the same way as m.  The removed items are already sorted, and they
+// are bulk-loaded into the new Map.  If lo is not less than hi, it
+// returns an empty Map, and m is unchanged.
+func (m *Map[Key, Value]) CutRange(lo, hi Key) *Map[Key, Value] {
+	r := m.newEmpty()
+	b := newBuilder(r)
+
+	for k, v := range m.DrainRange(lo, hi) {
+		b.add(k, v)
+	}
+
+	b.finish()
+
+	return r
+}
+
+// Partition returns two new Maps: yes contains the copies of the
+// items in m for which pred returns true, and no contains the rest.
+// The new Maps compare keys in the same way as m.  The items are
+// bulk-loaded into them.  m is unchanged.
+func (m *Map[Key, Value]) Partition(
+	pred func(key Key, value Value) bool,
+) (yes, no *Map[Key, Value]) {
+	yes = m.newEmpty()
+	no = m.newEmpty()
+
+	yb := newBuilder(yes)
+	nb := newBuilder(no)
+
+	for k, v := range m.Begin().Seq() {
+		if pred(k, v) {
+			yb.add(k, v)
+		} else {
+			nb.add(k, v)
+		}
+	}
+
+	yb.finish()
+	nb.finish()
+
+	return yes, no
+}
+
+// Reorder returns a new Map created by [NewAny] with compare that
+// contains the copies of the items in m.  m is unchanged.  If keys
+// are equal under compare, the one that is the largest in m wins.  It
+// takes O(n log n) time.
+func (m *Map[Key, Value]) Reorder(compare Compare[Key]) *Map[Key, Value] {
+	r := NewAny[Key, Value](compare)
+	keys, values := m.Begin().Collect()
+
+	// keys and values have the same length.
+	_ = r.ReplaceAll(keys, values)
+
+	return r
+}
+
+// newEmpty returns a new empty Map that compares and searches keys in
+// the same way as m.
+func (m *Map[Key, Value]) newEmpty() *Map[Key, Value] {
+	node := &leafNode[Key, Value]{}
+
+	return &Map[Key, Value]{
+		root:    node,
+		front:   node,
+		back:    node,
+		compare: m.compare,
+		search:  m.search,
+		minSize: m.minSize,
+	}
+}
+
+// RangeBackward returns an iterator over the items whose keys are in
+// [lo, hi) in the reverse sorted order.  If lo is not less than hi,
+// the iterator yields nothing.
+func (m *Map[Key, Value]) RangeBackward(lo, hi Key) iter.Seq2[Key, Value] {
+	return func(yield func(Key, Value) bool) {
+		if m.compare(lo, hi) >= 0 {
+			return
+		}
+
+		for it := m.LowerBound(hi); !it.Begin(); {
+			it = it.Prev()
+
+			if m.compare(it.Key(), lo) < 0 || !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// WalkLeaves calls fn for each leaf node in the sorted order with the
+// keys and values that the leaf node contains.  It stops if fn
+// returns false.  The slices refer to the internal storage of m, and
+// they are only valid until m is modified.  fn must not modify the
+// slices, nor m.
+func (m *Map[Key, Value]) WalkLeaves(
+	fn func(keys []Key, values []Value) bool,
+) {
+	if m.n == 0 {
+		return
+	}
+
+	for tnode := m.front; tnode != nil; tnode = tnode.next {
+		if !fn(tnode.keys[:tnode.n:tnode.n], tnode.values[:tnode.n:tnode.n]) {
+			return
+		}
+	}
+}
+
+// SeqRef returns an iterator over all keys and the pointers to their
+// values in the internal storage of m in the sorted order.  It avoids
+// copying the values, and the values can be modified through the
+// pointers.  A pointer must not be retained after the iteration
+// step, because it is invalidated by the changes in m.  m must not be
+// modified during iteration.
+func (m *Map[Key, Value]) SeqRef() iter.Seq2[Key, *Value] {
+	return func(yield func(Key, *Value) bool) {
+		for tnode := m.front; tnode != nil; tnode = tnode.next {
+			for i := range tnode.n {
+				if !yield(tnode.keys[i], &tnode.values[i]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Enumerate returns an iterator over all items in m in the sorted
+// order that yields the 0-based index of each item along with it.
+func (m *Map[Key, Value]) Enumerate() iter.Seq2[int, Entry[Key, Value]] {
+	return func(yield func(int, Entry[Key, Value]) bool) {
+		i := 0
+
+		for k, v := range m.Begin().Seq() {
+			if !yield(i, Entry[Key, Value]{Key: k, Value: v}) {
+				return
+			}
+
+			i++
+		}
+	}
+}
+
+// Chunks returns an iterator over the keys and values in m in the
+// sorted order that yields them in chunks, each of which contains the
+// items in a leaf node.  The slices refer to the internal storage of
+// m, and they are only valid until m is modified.  The caller must
+// not modify the slices, nor m during iteration.
+func (m *Map[Key, Value]) Chunks() iter.Seq2[[]Key, []Value] {
+	return func(yield func([]Key, []Value) bool) {
+		m.WalkLeaves(yield)
+	}
+}
+
+// Scan calls fn for each item in m in the sorted order with the
+// pointers to its key and value in the internal storage of m.  It
+// stops if fn returns false.  It copies neither Iterator nor the keys
+// and values.  The pointers are only valid during the call of fn.  fn
+// may modify the value through the pointer, but it must not modify
+// the key, nor m.
+func (m *Map[Key, Value]) Scan(fn func(key *Key, value *Value) bool) {
+	for tnode := m.front; tnode != nil; tnode = tnode.next {
+		for i := range tnode.n {
+			if !fn(&tnode.keys[i], &tnode.values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Snapshot copies all keys and values in m and returns an iterator
+// over the copy in the sorted order.  Unlike [Map.Begin], the
+// returned iterator is not invalidated by the changes in m made after
+// this call, and it does not observe them.  It requires O(n) memory.
+func (m *Map[Key, Value]) Snapshot() iter.Seq2[Key, Value] {
+	keys, values := m.Begin().Collect()
+
+	return func(yield func(Key, Value) bool) {
+		for i, key := range keys {
+			if !yield(key, values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// String returns the string representation of m.  Keys and values are
+// formatted in the same way as [Map.MarshalText], except that %v verb
+// is used if [encoding.TextMarshaler] fails.
 func (m *Map[Key, Value]) String() string {
-	var b strings.Builder
+	return m.StringN(m.n)
+}
 
-	b.WriteString("Map[")
+// StringN returns the string representation of m like
+// [Map.String], but it includes at most limit items.  If m has more
+// items, they are elided, and the number of them is shown instead.
+func (m *Map[Key, Value]) StringN(limit int) string {
+	b := []byte("Map[")
+	n := 0
 
+	for k, v := range m.Begin().Seq() {
+		if n > 0 {
+			b = append(b, ' ')
+		}
+
+		if n >= limit {
+			b = fmt.Appendf(b, "...(%d more)", m.n-n)
+
+			break
+		}
+
+		b = appendItem(b, k, v)
+
+		n++
+	}
+
+	b = append(b, ']')
+
+	return string(b)
+}
+
+// StringRange returns the string representation of m like
+// [Map.String], but it includes only the items whose keys are in the
+// range [lo, hi).  If lo >= hi, it returns "Map[]".
+func (m *Map[Key, Value]) StringRange(lo, hi Key) string {
+	b := []byte("Map[")
+	n := 0
+
+	for k, v := range m.Range(lo, hi) {
+		if n > 0 {
+			b = append(b, ' ')
+		}
+
+		b = appendItem(b, k, v)
+
+		n++
+	}
+
+	b = append(b, ']')
+
+	return string(b)
+}
+
+// appendItem appends the string representation of the item to dst in
+// the format of [Map.String].
+func appendItem[Key, Value any](dst []byte, key Key, value Value) []byte {
+	dst = appendString(dst, key)
+	dst = append(dst, ':')
+
+	return appendString(dst, value)
+}
+
+// EqualSlice returns true if m contains exactly the items given by
+// keys and the corresponding values in the same order.  keys must be
+// sorted.  Keys are compared by the comparison function of m, and
+// values are compared by valueEqual.  If keys and values have
+// different lengths, it returns false.  It walks the leaf nodes and
+// the slices in lockstep without building another Map.
+func (m *Map[Key, Value]) EqualSlice(
+	keys []Key, values []Value, valueEqual func(a, b Value) bool,
+) bool {
+	if len(keys) != len(values) || len(keys) != m.n {
+		return false
+	}
+
+	i := 0
+
+	for tnode := m.front; tnode != nil; tnode = tnode.next {
+		for j := range tnode.n {
+			if m.compare(tnode.keys[j], keys[i]) != 0 ||
+				!valueEqual(tnode.values[j], values[i]) {
+				return false
+			}
+
+			i++
+		}
+	}
+
+	return true
+}
+
+// Compact rebuilds the tree of m from its items so that leaf nodes
+// are fully packed.  It reduces the number of nodes and possibly the
+// height of the tree after many removals, which improves the locality
+// of subsequent lookups and iteration.  The contents of m are
+// unchanged.  It takes O(n) time.  Note that subsequent insertions
+// likely split the packed leaf nodes.
+func (m *Map[Key, Value]) Compact() {
+	b := newBuilder(m)
+
+	for it := m.Begin(); !it.End(); it = it.Next() {
+		b.add(it.Key(), it.Value())
+	}
+
+	b.finish()
+}
+
+// MergeSeq inserts the key-value pairs yielded by seq into m.  If a
+// key already exists, it replaces the existing value with
+// onConflict(existing, incoming).  seq should yield the keys in the
+// strictly increasing order.  Then MergeSeq merges them with the
+// items in m in a single pass, and rebuilds the tree in the same way
+// as [Map.Compact].  The pairs that are out of order are not lost;
+// they are inserted one by one after the pass as if by [Map.Upsert].
+// It takes O(n+k) time where k is the number of the pairs yielded by
+// seq, so that [Map.Upsert] is faster if k is much smaller than n.
+func (m *Map[Key, Value]) MergeSeq(
+	seq iter.Seq2[Key, Value],
+	onConflict func(existing, incoming Value) Value,
+) {
+	var (
+		rest    []Entry[Key, Value]
+		last    Key
+		hasLast bool
+	)
+
+	b := newBuilder(m)
 	it := m.Begin()
-	if !it.End() {
-		fmt.Fprintf(&b, "%v:%v", it.Key(), it.Value())
-		it = it.Next()
 
-		for k, v := range it.Seq() {
-			fmt.Fprintf(&b, " %v:%v", k, v)
+	for key, value := range seq {
+		if hasLast && m.compare(last, key) >= 0 {
+			rest = append(rest, Entry[Key, Value]{Key: key, Value: value})
+			continue
+		}
+
+		for ; !it.End() && m.compare(it.Key(), key) < 0; it = it.Next() {
+			b.add(it.KeyValue())
 		}
+
+		if !it.End() && m.compare(it.Key(), key) == 0 {
+			value = onConflict(it.Value(), value)
+			it = it.Next()
+		}
+
+		b.add(key, value)
+
+		last = key
+		hasLast = true
+	}
+
+	for ; !it.End(); it = it.Next() {
+		b.add(it.KeyValue())
+	}
+
+	b.finish()
+
+	for _, e := range rest {
+		m.Upsert(e.Key, e.Value, onConflict)
+	}
+}
+
+// ByteSize returns the estimated number of bytes that m occupies.
+// It is the sum of the sizes of Map and its nodes.  It does not
+// include the memory referenced by keys and values, such as the
+// contents of strings, slices, and pointers, and the nodes in the
+// node pool.
+func (m *Map[Key, Value]) ByteSize() int {
+	internals, leaves := m.countNodes()
+
+	return int(unsafe.Sizeof(*m)) +
+		internals*int(unsafe.Sizeof(internalNode[Key, Value]{})) +
+		leaves*int(unsafe.Sizeof(leafNode[Key, Value]{}))
+}
+
+// Stats returns the statistics of the nodes in m.  It takes O(n)
+// time.
+func (m *Map[Key, Value]) Stats() Stats {
+	st := Stats{
+		Height: m.height,
+	}
+
+	for tnode := m.front; tnode != nil; tnode = tnode.next {
+		st.Leaves++
+		st.Used += tnode.n
+	}
+
+	collectInternalStats(m.root, m.height, &st)
+
+	st.Capacity = (st.Leaves + st.Internals) * maxNodes
+	st.FillRatio = float64(st.Used) / float64(st.Capacity)
+
+	return st
+}
+
+// collectInternalStats adds the number of internal nodes and their
+// children in the subtree rooted at node whose height is height to
+// st.
+func collectInternalStats[Key, Value any](
+	node node[Key, Value], height int, st *Stats,
+) {
+	if height == 0 {
+		return
+	}
+
+	inode := node.(*internalNode[Key, Value])
+
+	st.Internals++
+	st.Used += inode.n
+
+	for _, node := range inode.nodes[:inode.n] {
+		collectInternalStats(node, height-1, st)
 	}
+}
+
+// countNodes returns the number of internal nodes and leaf nodes in
+// m.
+func (m *Map[Key, Value]) countNodes() (int, int) {
+	leaves := 0
 
-	b.WriteString("]")
+	for tnode := m.front; tnode != nil; tnode = tnode.next {
+		leaves++
+	}
 
-	return b.String()
+	return countInternalNodes(m.root, m.height), leaves
+}
+
+// countInternalNodes returns the number of internal nodes in the
+// subtree rooted at node whose height is height.
+func countInternalNodes[Key, Value any](
+	node node[Key, Value], height int,
+) int {
+	if height == 0 {
+		return 0
+	}
+
+	inode := node.(*internalNode[Key, Value])
+	n := 1
+
+	for _, node := range inode.nodes[:inode.n] {
+		n += countInternalNodes(node, height-1)
+	}
+
+	return n
 }
 
 // Clear removes all items from m.
@@ -476,9 +2448,33 @@ func (m *Map[Key, Value]) Clear() {
 		return
 	}
 
-	node := &leafNode[Key, Value]{}
+	if m.pool != nil {
+		m.freeTree(m.root)
+	}
+
+	node := m.newLeafNode()
 	m.root = node
 	m.front = node
 	m.back = node
 	m.n = 0
+	m.height = 0
+	m.cache = nil
+}
+
+// ClearZeroing is [Map.Clear] that also zeroes the keys and values
+// held by the nodes before dropping them.  The objects referenced by
+// the items can then be reclaimed by GC even if a node is still
+// referenced by a stale [Iterator].  It takes O(n) time to visit all
+// items, while [Map.Clear] drops the tree in O(1) time unless the
+// node pool is enabled.  If the node pool is enabled, [Map.Clear]
+// already zeroes the nodes, and both are equivalent.
+func (m *Map[Key, Value]) ClearZeroing() {
+	if m.pool == nil {
+		for tnode := m.front; tnode != nil; tnode = tnode.next {
+			clear(tnode.keys[:tnode.n])
+			clear(tnode.values[:tnode.n])
+		}
+	}
+
+	m.Clear()
 }
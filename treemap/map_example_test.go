@@ -130,6 +130,20 @@ func ExampleMap_Insert() {
 	// 200 true
 }
 
+func ExampleMap_Append() {
+	m := New[int, string]()
+
+	fmt.Println(m.Append(1, "alpha"))
+	fmt.Println(m.Append(2, "bravo"))
+	fmt.Println(m.Append(2, "charlie"))
+	fmt.Println(m)
+	// Output:
+	// true
+	// true
+	// false
+	// Map[1:alpha 2:bravo]
+}
+
 func ExampleMap_Remove() {
 	m := New[int, string]()
 
@@ -159,6 +173,23 @@ func ExampleMap_RemoveIter() {
 	// 3 charlie
 }
 
+func ExampleMap_Prune() {
+	m := New[int, string]()
+
+	m.Insert(1, "alpha")
+	m.Insert(2, "bravo")
+	m.Insert(3, "charlie")
+	m.Insert(4, "delta")
+
+	n := m.Prune(func(key int, _ string) bool {
+		return key%2 == 0
+	})
+
+	fmt.Println(n, slices.Collect(m.Keys()))
+	// Output:
+	// 2 [1 3]
+}
+
 func ExampleMap_Keys() {
 	m := New[int, int]()
 
@@ -182,3 +213,17 @@ func ExampleMap_Values() {
 	// Output:
 	// [100 200 300]
 }
+
+func ExampleMap_RBegin() {
+	m := New[int, string]()
+
+	m.Insert(0, "foo")
+	m.Insert(1, "bar")
+
+	for it := m.RBegin(); !it.REnd(); it = it.Prev() {
+		fmt.Println(it.Key(), it.Value())
+	}
+	// Output:
+	// 1 bar
+	// 0 foo
+}
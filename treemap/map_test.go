@@ -28,8 +28,12 @@ import (
 	"fmt"
 	"iter"
 	"math"
+	"math/rand/v2"
 	"slices"
+	"strconv"
 	"testing"
+	"time"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,6 +47,7 @@ func verifyMapNode[Key, Value any](
 
 	switch node := node.(type) {
 	case *internalNode[Key, Value]:
+		verifyMinNodes(t, node, m)
 		verifyClear(t, node.nodes[node.n:])
 		verifyClear(t, node.keys[node.n:])
 		assert.True(t, slices.IsSortedFunc(node.keys[:node.n],
@@ -53,6 +58,7 @@ func verifyMapNode[Key, Value any](
 			verifyMapNode(t, tnode, node.keys[i], m)
 		}
 	case *leafNode[Key, Value]:
+		verifyMinNodes(t, node, m)
 		verifyClear(t, node.values[node.n:])
 		verifyClear(t, node.keys[node.n:])
 		assert.True(t, slices.IsSortedFunc(node.keys[:node.n],
@@ -61,6 +67,16 @@ func verifyMapNode[Key, Value any](
 	}
 }
 
+func verifyMinNodes[Key, Value any](
+	t *testing.T, node node[Key, Value], m *Map[Key, Value],
+) {
+	t.Helper()
+
+	if node != m.root {
+		assert.GreaterOrEqual(t, node.Size(), m.minSize)
+	}
+}
+
 func verifyClear[T any](t *testing.T, v []T) {
 	t.Helper()
 
@@ -108,6 +124,7 @@ func verifyMap[Key, Value any](
 	verifyMapNode(t, m.root, maxKey, m)
 	verifyMapLen(t, m)
 	verifyMapKey(t, m, minKey)
+	require.NoError(t, m.Validate())
 }
 
 func printMap[Key, Value any](m *Map[Key, Value]) { //nolint:unused
@@ -178,6 +195,41 @@ func TestMapInsert(t *testing.T) {
 		slices.Collect(m.Values()))
 }
 
+func TestMapUpsert(t *testing.T) {
+	m := New[string, int]()
+
+	add := func(existing, incoming int) int {
+		return existing + incoming
+	}
+
+	it, ok := m.Upsert("foo", 1, add)
+
+	assert.False(t, ok)
+	assert.Equal(t, "foo", it.Key())
+	assert.Equal(t, 1, it.Value())
+
+	it, ok = m.Upsert("foo", 2, add)
+
+	assert.True(t, ok)
+	assert.Equal(t, "foo", it.Key())
+	assert.Equal(t, 3, it.Value())
+
+	m2 := New[int, int]()
+
+	for i := range 3000 {
+		m2.Upsert(i%1000, i, add)
+	}
+
+	verifyMap(t, m2, 0, 999)
+
+	for i := range 1000 {
+		v, ok := m2.Find(i)
+
+		require.True(t, ok)
+		assert.Equal(t, i*3+3000, v)
+	}
+}
+
 func TestMapInsertSplitNode(t *testing.T) {
 	m := New[int, int]()
 
@@ -225,6 +277,16 @@ func TestMapInsertSplitMiddle(t *testing.T) {
 	assert.Equal(t, keys, slices.Collect(m.Keys()))
 }
 
+func collectKeys[Key, Value any](seq iter.Seq2[Key, Value]) []Key {
+	var keys []Key
+
+	for k := range seq {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
 func genIntSeq(n int) iter.Seq[int] {
 	return func(yield func(int) bool) {
 		for i := range n {
@@ -399,6 +461,49 @@ func TestMapRemoveIter1000(t *testing.T) {
 	verifyMap(t, m, 1, 999)
 }
 
+func TestMapRemoveIterZeroing(t *testing.T) {
+	for _, lazy := range []bool{false, true} {
+		m := New[int, *int]()
+		if lazy {
+			m.UseLazyRebalance()
+		}
+
+		for i := range 1000 {
+			m.Insert(i, &i)
+		}
+
+		// Remove two of every three items, so that both the removal
+		// within the leaf node and the one that rebalances the tree
+		// happen.
+		for it := m.Begin(); !it.End(); {
+			if it.Key()%3 == 0 {
+				it = it.Next()
+				continue
+			}
+
+			key := it.Key()
+			it = m.RemoveIter(it)
+
+			require.False(t, it.End())
+			assert.Equal(t, key+1, it.Key())
+
+			for tnode := m.front; tnode != nil; tnode = tnode.next {
+				for i := tnode.n; i < maxNodes; i++ {
+					if tnode.values[i] != nil || tnode.keys[i] != 0 {
+						require.Failf(t, "stale item",
+							"slot %d of leaf node is not cleared", i)
+					}
+				}
+			}
+		}
+
+		assert.Equal(t, slices.Collect(genIntSeqStep(0, 1000, 3)),
+			slices.Collect(m.Keys()))
+
+		verifyMap(t, m, 0, 999)
+	}
+}
+
 func TestMapRemoveIterNextNode(t *testing.T) {
 	m := New[int, int]()
 
@@ -419,193 +524,2203 @@ func TestMapRemoveIterNextNode(t *testing.T) {
 	assert.Equal(t, 24, it.Key())
 }
 
-func TestMapFind(t *testing.T) {
+func TestMapAppend(t *testing.T) {
 	m := New[int, int]()
 
-	m.Insert(98, 1)
-	m.Insert(96, 2)
-	m.Insert(99, 3)
+	for i := range 1000 {
+		require.True(t, m.Append(i*2, i))
 
-	v, ok := m.Find(96)
+		verifyMap(t, m, 0, i*2)
+	}
 
-	require.True(t, ok)
-	assert.Equal(t, 2, v)
+	assert.False(t, m.Append(1998, 0))
+	assert.False(t, m.Append(1000, 0))
+	assert.False(t, m.Append(-1, 0))
+	assert.Equal(t, 1000, m.Len())
 
-	v, ok = m.Find(98)
+	for i := range 1000 {
+		m.Insert(i*2+1, i)
+	}
 
-	require.True(t, ok)
-	assert.Equal(t, 1, v)
+	verifyMap(t, m, 0, 1999)
 
-	v, ok = m.Find(99)
+	assert.Equal(t, slices.Collect(genIntSeq(2000)),
+		slices.Collect(m.Keys()))
+
+	v, ok := m.Find(1998)
 
 	require.True(t, ok)
-	assert.Equal(t, 3, v)
+	assert.Equal(t, 999, v)
+}
 
-	_, ok = m.Find(100)
+func TestMapBulkAppend(t *testing.T) {
+	m := New[int, int]()
 
-	assert.False(t, ok)
+	require.NoError(t, m.BulkAppend(nil, nil))
+	assert.Equal(t, 0, m.Len())
 
-	_, ok = m.Find(97)
+	keys := slices.Collect(genIntSeqStep(0, 2000, 2))
+	values := slices.Collect(genIntSeq(1000))
 
-	assert.False(t, ok)
+	require.NoError(t, m.BulkAppend(keys, values))
 
-	_, ok = m.Find(95)
+	verifyMap(t, m, 0, 1998)
 
-	assert.False(t, ok)
+	assert.Equal(t, keys, slices.Collect(m.Keys()))
+	assert.Equal(t, values, slices.Collect(m.Values()))
+
+	require.NoError(t, m.BulkAppend([]int{2000, 2001}, []int{1, 2}))
+
+	verifyMap(t, m, 0, 2001)
+
+	assert.Equal(t, 1002, m.Len())
+
+	keys = slices.Collect(genIntSeqStep(3000, 6000, 1))
+	values = slices.Collect(genIntSeq(3000))
+
+	require.NoError(t, m.BulkAppend(keys, values))
+
+	verifyMap(t, m, 0, 5999)
+
+	assert.Equal(t, 4002, m.Len())
+
+	for i := range 1000 {
+		m.Insert(i*2+1, i)
+	}
+
+	verifyMap(t, m, 0, 5999)
+
+	keys, _ = m.Begin().Collect()
+
+	assert.Equal(t, 5002, m.Len())
+	assert.Equal(t, slices.Collect(genIntSeq(2002)), keys[:2002])
 }
 
-func TestMapFind1000(t *testing.T) {
+func TestMapBulkAppendSizes(t *testing.T) {
+	for _, lazy := range []bool{false, true} {
+		for _, base := range []int{0, 1, 31, 32, 33, 500, 2048} {
+			for _, k := range []int{
+				1, 15, 16, 17, 31, 32, 33, 48, 100, 1000, 5000,
+			} {
+				m := New[int, int]()
+				if lazy {
+					m.UseLazyRebalance()
+				}
+
+				for i := range base {
+					m.Insert(i, i)
+				}
+
+				front := m.front
+				keys := slices.Collect(genIntSeqStep(base, base+k, 1))
+
+				require.NoError(t, m.BulkAppend(keys, keys))
+				require.NoError(t, m.Validate(), "base=%d k=%d", base, k)
+
+				if base > 0 {
+					assert.Same(t, front, m.front)
+				}
+
+				assert.Equal(t, base+k, m.Len())
+				assert.Equal(t, slices.Collect(genIntSeq(base+k)),
+					slices.Collect(m.Keys()))
+
+				for i := 0; i < base+k; i += 3 {
+					m.Remove(i)
+				}
+
+				m.Insert(-1, 0)
+				m.Insert(base+k, 0)
+
+				require.NoError(t, m.Validate(), "base=%d k=%d", base, k)
+			}
+		}
+	}
+}
+
+func TestMapBulkAppendError(t *testing.T) {
 	m := New[int, int]()
 
-	for i := range 1000 {
-		m.Insert(i, i+1)
+	require.Error(t, m.BulkAppend([]int{1, 2}, []int{1}))
+	require.ErrorIs(t, m.BulkAppend([]int{1, 1}, []int{1, 2}),
+		ErrDuplicateKey)
+	require.ErrorIs(t, m.BulkAppend([]int{2, 1}, []int{1, 2}),
+		ErrOutOfOrder)
+
+	assert.Equal(t, 0, m.Len())
+
+	require.NoError(t, m.BulkAppend([]int{1, 2}, []int{1, 2}))
+	require.ErrorIs(t, m.BulkAppend([]int{2, 3}, []int{1, 2}),
+		ErrDuplicateKey)
+	require.ErrorIs(t, m.BulkAppend([]int{0}, []int{1}), ErrOutOfOrder)
+	require.ErrorIs(t, m.BulkAppend([]int{3, 4, 4}, []int{1, 2, 3}),
+		ErrDuplicateKey)
+
+	assert.Equal(t, []int{1, 2}, slices.Collect(m.Keys()))
+}
+
+func TestMapInsertStats(t *testing.T) {
+	m := New[int, int]()
+
+	for i := range maxNodes {
+		_, _, ok, splits := m.InsertStats(i, i)
+
+		assert.False(t, ok)
+		assert.Equal(t, 0, splits)
 	}
 
-	v, ok := m.Find(111)
+	_, _, _, splits := m.InsertStats(maxNodes, 0)
 
-	assert.True(t, ok)
-	assert.Equal(t, 112, v)
+	assert.Equal(t, 1, splits)
+
+	it, old, ok, splits := m.InsertStats(0, 100)
+
+	require.True(t, ok)
+	assert.Equal(t, 0, old)
+	assert.Equal(t, 100, it.Value())
+	assert.Equal(t, 0, splits)
+
+	total := 1
+
+	for i := range 100000 {
+		_, _, _, splits := m.InsertStats(i, i)
+
+		total += splits
+	}
+
+	// Every split adds a node, and every root split also adds a new
+	// root.
+	internals, leaves := m.countNodes()
+
+	assert.Equal(t, internals+leaves-1-m.height, total)
 }
 
-func TestMapFindAfterRemove(t *testing.T) {
+func TestMapInsertNew(t *testing.T) {
 	m := New[int, int]()
 
 	for i := range 1000 {
-		m.Insert(i, i+1)
+		it, ok := m.InsertNew(i, i)
+
+		require.True(t, ok)
+		assert.Equal(t, i, it.Key())
+		assert.Equal(t, i, it.Value())
 	}
 
-	m.Remove(15)
-	m.Remove(511)
+	for i := range 1000 {
+		it, ok := m.InsertNew(i, -1)
 
-	for i := 495; i <= 510; i++ {
-		m.Remove(i)
+		require.False(t, ok)
+		assert.Equal(t, i, it.Key())
+		assert.Equal(t, i, it.Value())
 	}
 
-	_, ok := m.Find(511)
+	assert.Equal(t, 1000, m.Len())
 
-	assert.False(t, ok)
+	verifyMap(t, m, 0, 999)
+}
 
-	it := m.LowerBound(511)
+func TestMapInsertWithNeighbors(t *testing.T) {
+	m := New[int, int]()
 
-	require.False(t, it.End())
-	assert.Equal(t, 512, it.Key())
+	prev, cur, next, replaced := m.InsertWithNeighbors(10, 1)
+
+	assert.False(t, replaced)
+	assert.Equal(t, m.REnd(), prev)
+	assert.Equal(t, 10, cur.Key())
+	assert.True(t, next.End())
+
+	for i := range 100 {
+		m.Insert(i*20, i)
+	}
+
+	for i := 1; i < 99; i++ {
+		prev, cur, next, replaced = m.InsertWithNeighbors(i*20+5, -i)
+
+		assert.False(t, replaced)
+		assert.Equal(t, i*20, prev.Key())
+		assert.Equal(t, i*20+5, cur.Key())
+		assert.Equal(t, -i, cur.Value())
+		assert.Equal(t, (i+1)*20, next.Key())
+	}
+
+	prev, cur, next, replaced = m.InsertWithNeighbors(0, 7)
+
+	assert.True(t, replaced)
+	assert.Equal(t, m.REnd(), prev)
+	assert.Equal(t, m.Begin(), cur)
+	assert.Equal(t, 7, cur.Value())
+	assert.Equal(t, 10, next.Key())
+
+	prev, cur, next, replaced = m.InsertWithNeighbors(2000, 1)
+
+	assert.False(t, replaced)
+	assert.Equal(t, 1980, prev.Key())
+	assert.Equal(t, m.End().Prev(), cur)
+	assert.Equal(t, m.End(), next)
+
+	verifyMap(t, m, 0, 2000)
 }
 
-func TestMapLowerBound(t *testing.T) {
+func TestMapInsertNext(t *testing.T) {
 	m := New[int, int]()
 
-	m.Insert(98, 1)
-	m.Insert(96, 2)
-	m.Insert(99, 3)
+	it := m.InsertNext(10, 1)
 
-	it := m.LowerBound(95)
+	assert.True(t, it.End())
 
-	require.False(t, it.End())
-	assert.Equal(t, 96, it.Key())
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
 
-	it = m.LowerBound(96)
+	for i := range 999 {
+		it := m.InsertNext(i*2+1, -1)
 
-	require.False(t, it.End())
-	assert.Equal(t, 96, it.Key())
+		require.False(t, it.End())
+		assert.Equal(t, i*2+2, it.Key())
+	}
 
-	it = m.LowerBound(97)
+	it = m.InsertNext(10, 100)
 
 	require.False(t, it.End())
-	assert.Equal(t, 98, it.Key())
+	assert.Equal(t, 11, it.Key())
 
-	it = m.LowerBound(99)
+	v, ok := m.Find(10)
 
-	require.False(t, it.End())
-	assert.Equal(t, 99, it.Key())
+	require.True(t, ok)
+	assert.Equal(t, 100, v)
 
-	it = m.LowerBound(100)
+	assert.True(t, m.InsertNext(1998, 0).End())
 
-	require.True(t, it.End())
+	verifyMap(t, m, 0, 1998)
 }
 
-func TestLowerBoundNextNode(t *testing.T) {
+func TestMapRemoveKey(t *testing.T) {
 	m := New[int, int]()
 
-	for i := range 48 {
-		m.Insert(i, i)
-	}
+	_, it, ok := m.RemoveKey(1)
 
-	m.Remove(15)
-	m.Remove(23)
+	assert.False(t, ok)
+	assert.True(t, it.End())
 
-	it := m.LowerBound(23)
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
 
-	require.False(t, it.End())
-	assert.Equal(t, 24, it.Key())
+	_, it, ok = m.RemoveKey(1)
 
-	m = New[int, int]()
+	assert.False(t, ok)
+	assert.True(t, it.End())
+	assert.Equal(t, 1000, m.Len())
 
-	for i := range 1000 {
-		m.Insert(i, i)
+	for i := range 999 {
+		v, it, ok := m.RemoveKey(i * 2)
+
+		require.True(t, ok)
+		assert.Equal(t, i, v)
+		require.False(t, it.End())
+		assert.Equal(t, i*2+2, it.Key())
+		assert.Equal(t, m.Begin(), it)
 	}
 
-	it = m.LowerBound(1000)
+	v, it, ok := m.RemoveKey(1998)
 
-	require.True(t, it.End())
+	require.True(t, ok)
+	assert.Equal(t, 999, v)
+	assert.True(t, it.End())
+	assert.Equal(t, 0, m.Len())
 }
 
-func TestMapKeys(t *testing.T) {
+func TestMapRemoveMinMax(t *testing.T) {
 	m := New[int, int]()
 
-	keys := []int{3, 7, 9}
+	assert.False(t, m.RemoveMin())
+	assert.False(t, m.RemoveMax())
 
-	for _, k := range keys {
-		m.Insert(k, k)
+	for i := range 1000 {
+		m.Insert(i, i)
 	}
 
-	assert.Equal(t, keys, slices.Collect(m.Keys()))
+	for i := range 500 {
+		require.True(t, m.RemoveMin())
+		require.True(t, m.RemoveMax())
 
-	for range m.Keys() {
-		break
+		if m.Empty() {
+			break
+		}
+
+		assert.Equal(t, i+1, m.Begin().Key())
+		assert.Equal(t, 998-i, m.End().Prev().Key())
+
+		if i%50 == 0 {
+			verifyMap(t, m, i+1, 999)
+		}
 	}
+
+	assert.True(t, m.Empty())
+	assert.False(t, m.RemoveMin())
+	assert.False(t, m.RemoveMax())
 }
 
-func TestMapValues(t *testing.T) {
+func TestMapClearRange(t *testing.T) {
 	m := New[int, int]()
 
-	keys := []int{3, 7, 9}
-	values := []int{8, 1, 5}
+	assert.Same(t, m, m.ClearRange(0, 100))
 
-	for i, k := range keys {
-		m.Insert(k, values[i])
+	for i := range 1000 {
+		m.Insert(i, i)
 	}
 
-	assert.Equal(t, values, slices.Collect(m.Values()))
+	assert.Equal(t, 1000, m.ClearRange(100, 100).ClearRange(100, 0).Len())
+	assert.Equal(t, 700, m.ClearRange(100, 300).ClearRange(900, 1000).Len())
+	assert.Equal(t, slices.Concat(
+		slices.Collect(genIntSeqStep(0, 100, 1)),
+		slices.Collect(genIntSeqStep(300, 900, 1)),
+	), slices.Collect(m.Keys()))
 
-	for range m.Values() {
-		break
-	}
+	verifyMap(t, m, 0, 999)
 }
 
-func TestMapNewAny(t *testing.T) {
-	m := NewAny[string, int](cmp.Compare[string])
+func TestMapPrune(t *testing.T) {
+	m := New[int, int]()
 
-	m.Insert("foo", 1)
-	m.Insert("bar", 2)
+	assert.Equal(t, 0, m.Prune(func(int, int) bool { return true }))
 
-	assert.Equal(t, []string{"bar", "foo"}, slices.Collect(m.Keys()))
-}
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
 
-func TestMapString(t *testing.T) {
-	m := New[int, string]()
+	assert.Equal(t, 0, m.Prune(func(int, int) bool { return false }))
+	assert.Equal(t, 1000, m.Len())
 
-	assert.Equal(t, "Map[]", m.String())
+	var keys []int
 
-	m.Insert(1, "foo")
-	m.Insert(2, "bar")
+	n := m.Prune(func(k, v int) bool {
+		assert.Equal(t, k+1, v)
 
-	assert.Equal(t, "Map[1:foo 2:bar]", m.String())
+		keys = append(keys, k)
+
+		return k%3 != 0
+	})
+
+	assert.Equal(t, slices.Collect(genIntSeqStep(0, 1000, 1)), keys)
+	assert.Equal(t, 666, n)
+	assert.Equal(t, 334, m.Len())
+	assert.Equal(t, slices.Collect(genIntSeqStep(0, 1000, 3)),
+		slices.Collect(m.Keys()))
+
+	verifyMap(t, m, 0, 999)
+
+	assert.Equal(t, 334, m.Prune(func(int, int) bool { return true }))
+	assert.Equal(t, 0, m.Len())
+	assert.True(t, m.Begin().End())
 }
 
-func TestMapInsertRemoveSplitExtendKey(t *testing.T) {
-	m := New[uint64, int]()
+func TestMapUpdateIf(t *testing.T) {
+	m := New[int, int]()
 
-	m.Insert(9631484016779065335, 0)
+	assert.Equal(t, 0, m.UpdateIf(func(int, int) bool { return true },
+		func(v int) int { return v }))
+
+	for i := range 1000 {
+		m.Insert(i, i)
+	}
+
+	n := m.UpdateIf(func(_, v int) bool {
+		return v > 100
+	}, func(int) int {
+		return 100
+	})
+
+	assert.Equal(t, 899, n)
+
+	for k, v := range m.Begin().Seq() {
+		assert.Equal(t, min(k, 100), v)
+	}
+
+	n = m.UpdateIf(func(k, _ int) bool {
+		return k%2 == 0
+	}, func(v int) int {
+		return -v
+	})
+
+	assert.Equal(t, 500, n)
+
+	v, ok := m.Find(998)
+
+	require.True(t, ok)
+	assert.Equal(t, -100, v)
+
+	v, ok = m.Find(51)
+
+	require.True(t, ok)
+	assert.Equal(t, 51, v)
+
+	verifyMap(t, m, 0, 999)
+}
+
+func TestMapDrainRange(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Empty(t, Collect(m.DrainRange(0, 100)))
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
+
+	assert.Empty(t, Collect(m.DrainRange(100, 100)))
+	assert.Empty(t, Collect(m.DrainRange(100, 0)))
+	assert.Equal(t, 1000, m.Len())
+
+	var keys []int
+
+	for k, v := range m.DrainRange(100, 600) {
+		assert.Equal(t, k+1, v)
+
+		keys = append(keys, k)
+	}
+
+	assert.Equal(t, slices.Collect(genIntSeqStep(100, 600, 1)), keys)
+	assert.Equal(t, 500, m.Len())
+
+	verifyMap(t, m, 0, 999)
+
+	_, ok := m.Find(100)
+
+	assert.False(t, ok)
+
+	for k := range m.DrainRange(0, 1000) {
+		if k == 10 {
+			break
+		}
+	}
+
+	assert.Equal(t, 489, m.Len())
+	assert.Equal(t, 11, m.Begin().Key())
+
+	verifyMap(t, m, 11, 999)
+}
+
+func TestMapFindIter(t *testing.T) {
+	m := New[int, int]()
+
+	it, ok := m.FindIter(1)
+
+	assert.False(t, ok)
+	assert.True(t, it.End())
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	for i := range 2000 {
+		it, ok := m.FindIter(i)
+		if i%2 == 1 {
+			assert.False(t, ok)
+			assert.True(t, it.End())
+
+			continue
+		}
+
+		require.True(t, ok)
+		assert.Equal(t, i, it.Key())
+		assert.Equal(t, i/2, it.Value())
+	}
+}
+
+func TestNearest(t *testing.T) {
+	dist := func(a, b int) int {
+		if a < b {
+			return b - a
+		}
+
+		return a - b
+	}
+
+	m := New[int, string]()
+
+	_, _, ok := Nearest(m, 0, dist)
+
+	assert.False(t, ok)
+
+	m.Insert(10, "a")
+	m.Insert(20, "b")
+	m.Insert(30, "c")
+
+	for _, tc := range []struct {
+		key   int
+		want  int
+		value string
+	}{
+		{key: 0, want: 10, value: "a"},
+		{key: 10, want: 10, value: "a"},
+		{key: 14, want: 10, value: "a"},
+		{key: 15, want: 10, value: "a"},
+		{key: 16, want: 20, value: "b"},
+		{key: 20, want: 20, value: "b"},
+		{key: 26, want: 30, value: "c"},
+		{key: 100, want: 30, value: "c"},
+	} {
+		k, v, ok := Nearest(m, tc.key, dist)
+
+		require.True(t, ok)
+		assert.Equal(t, tc.want, k, "key=%d", tc.key)
+		assert.Equal(t, tc.value, v, "key=%d", tc.key)
+	}
+
+	f := New[float64, int]()
+
+	f.Insert(1.2, 1)
+	f.Insert(1.5, 2)
+
+	// The coarse distance makes 1.2 as close to 1.5 as 1.5 itself,
+	// but the exact match wins.
+	k, v, ok := Nearest(f, 1.5, func(a, b float64) int {
+		return int(math.Abs(a - b))
+	})
+
+	require.True(t, ok)
+	assert.InDelta(t, 1.5, k, 0)
+	assert.Equal(t, 2, v)
+}
+
+func TestMapGetMany(t *testing.T) {
+	m := New[int, int]()
+
+	values, found := m.GetMany([]int{1, 2})
+
+	assert.Equal(t, []int{0, 0}, values)
+	assert.Equal(t, []bool{false, false}, found)
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	values, found = m.GetMany(nil)
+
+	assert.Empty(t, values)
+	assert.Empty(t, found)
+
+	keys := slices.Collect(genIntSeqStep(-1, 2002, 1))
+	rkeys := slices.Clone(keys)
+
+	slices.Reverse(rkeys)
+
+	for _, keys := range [][]int{keys, rkeys} {
+		values, found := m.GetMany(keys)
+
+		require.Len(t, values, len(keys))
+		require.Len(t, found, len(keys))
+
+		for i, k := range keys {
+			v, ok := m.Find(k)
+
+			assert.Equal(t, ok, found[i])
+			assert.Equal(t, v, values[i])
+		}
+	}
+
+	values, found = m.GetMany([]int{10, 3, 10, 1998, 0})
+
+	assert.Equal(t, []int{5, 0, 5, 999, 0}, values)
+	assert.Equal(t, []bool{true, false, true, true, true}, found)
+}
+
+func TestMapContainsMany(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Equal(t, []bool{false}, m.ContainsMany([]int{1}))
+
+	for i := range 100 {
+		m.Insert(i*2, i)
+	}
+
+	assert.Equal(t, []bool{true, false, true, false},
+		m.ContainsMany([]int{198, 3, 0, 200}))
+	assert.Empty(t, m.ContainsMany(nil))
+}
+
+func TestMapFind(t *testing.T) {
+	m := New[int, int]()
+
+	m.Insert(98, 1)
+	m.Insert(96, 2)
+	m.Insert(99, 3)
+
+	v, ok := m.Find(96)
+
+	require.True(t, ok)
+	assert.Equal(t, 2, v)
+
+	v, ok = m.Find(98)
+
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	v, ok = m.Find(99)
+
+	require.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	_, ok = m.Find(100)
+
+	assert.False(t, ok)
+
+	_, ok = m.Find(97)
+
+	assert.False(t, ok)
+
+	_, ok = m.Find(95)
+
+	assert.False(t, ok)
+}
+
+func TestMapGetOr(t *testing.T) {
+	m := New[string, int]()
+
+	assert.Equal(t, -1, m.GetOr("foo", -1))
+
+	m.Insert("foo", 1)
+	m.Insert("bar", 0)
+
+	assert.Equal(t, 1, m.GetOr("foo", -1))
+	assert.Equal(t, 0, m.GetOr("bar", -1))
+	assert.Equal(t, -1, m.GetOr("baz", -1))
+}
+
+func TestMapReplace(t *testing.T) {
+	m := New[int, string]()
+
+	oldValue, ok := m.Replace(1, "foo")
+
+	assert.False(t, ok)
+	assert.Empty(t, oldValue)
+	assert.Equal(t, 0, m.Len())
+
+	for i := range 1000 {
+		m.Insert(i*2, "foo")
+	}
+
+	for i := range 2000 {
+		oldValue, ok := m.Replace(i, "bar")
+
+		if i%2 == 0 {
+			assert.True(t, ok)
+			assert.Equal(t, "foo", oldValue)
+		} else {
+			assert.False(t, ok)
+			assert.Empty(t, oldValue)
+		}
+	}
+
+	assert.Equal(t, 1000, m.Len())
+
+	for v := range m.Values() {
+		assert.Equal(t, "bar", v)
+	}
+}
+
+func TestMapFind1000(t *testing.T) {
+	m := New[int, int]()
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
+
+	v, ok := m.Find(111)
+
+	assert.True(t, ok)
+	assert.Equal(t, 112, v)
+}
+
+func TestMapFindAfterRemove(t *testing.T) {
+	m := New[int, int]()
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
+
+	m.Remove(15)
+	m.Remove(511)
+
+	for i := 495; i <= 510; i++ {
+		m.Remove(i)
+	}
+
+	_, ok := m.Find(511)
+
+	assert.False(t, ok)
+
+	it := m.LowerBound(511)
+
+	require.False(t, it.End())
+	assert.Equal(t, 512, it.Key())
+}
+
+func TestMapLocateInsert(t *testing.T) {
+	m := New[int, int]()
+
+	it, found := m.LocateInsert(1)
+
+	assert.True(t, it.End())
+	assert.False(t, found)
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	for i := range 2000 {
+		it, found := m.LocateInsert(i)
+
+		assert.Equal(t, m.LowerBound(i), it)
+		assert.Equal(t, i%2 == 0, found)
+	}
+
+	it, found = m.LocateInsert(2000)
+
+	assert.True(t, it.End())
+	assert.False(t, found)
+
+	m.UseBinarySearch()
+
+	it, found = m.LocateInsert(1000)
+
+	require.True(t, found)
+	assert.Equal(t, 500, it.Value())
+
+	it, found = m.LocateInsert(1001)
+
+	require.False(t, found)
+	assert.Equal(t, 1002, it.Key())
+}
+
+func TestMapLowerBoundFunc(t *testing.T) {
+	m := New[int, int]()
+
+	assert.True(t, m.LowerBoundFunc(func(int) int { return 0 }).End())
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	for i := 0; i < 2000; i += 7 {
+		m.Remove(i)
+	}
+
+	for i := -10; i < 2010; i++ {
+		it := m.LowerBoundFunc(func(k int) int {
+			return cmp.Compare(k, i)
+		})
+
+		assert.Equal(t, m.LowerBound(i), it)
+	}
+
+	type pair struct {
+		a, b int
+	}
+
+	p := NewAny[pair, int](func(x, y pair) int {
+		if c := cmp.Compare(x.a, y.a); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(x.b, y.b)
+	})
+
+	for i := range 100 {
+		for j := range 10 {
+			p.Insert(pair{i * 2, j}, i)
+		}
+	}
+
+	it := p.LowerBoundFunc(func(k pair) int {
+		return cmp.Compare(k.a, 51)
+	})
+
+	assert.Equal(t, pair{52, 0}, it.Key())
+
+	it = p.LowerBoundFunc(func(k pair) int {
+		return cmp.Compare(k.a, 198)
+	})
+
+	assert.Equal(t, pair{198, 0}, it.Key())
+
+	it = p.LowerBoundFunc(func(k pair) int {
+		return cmp.Compare(k.a, 199)
+	})
+
+	assert.True(t, it.End())
+}
+
+func TestMapLowerBoundFrom(t *testing.T) {
+	m := New[int, int]()
+
+	assert.True(t, m.LowerBoundFrom(m.Begin(), 1).End())
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	for _, step := range []int{1, 3, 10, 97, 500} {
+		hint := m.Begin()
+
+		for key := -1; key < 2010; key += step {
+			hint = m.LowerBoundFrom(hint, key)
+
+			assert.Equal(t, m.LowerBound(key), hint, "key=%d", key)
+		}
+	}
+
+	hint := m.LowerBound(1000)
+
+	assert.Equal(t, m.LowerBound(10), m.LowerBoundFrom(hint, 10))
+	assert.Equal(t, m.LowerBound(10), m.LowerBoundFrom(m.End(), 10))
+	assert.Equal(t, m.LowerBound(10), m.LowerBoundFrom(m.REnd(), 10))
+}
+
+func TestMapLowerBound(t *testing.T) {
+	m := New[int, int]()
+
+	m.Insert(98, 1)
+	m.Insert(96, 2)
+	m.Insert(99, 3)
+
+	it := m.LowerBound(95)
+
+	require.False(t, it.End())
+	assert.Equal(t, 96, it.Key())
+
+	it = m.LowerBound(96)
+
+	require.False(t, it.End())
+	assert.Equal(t, 96, it.Key())
+
+	it = m.LowerBound(97)
+
+	require.False(t, it.End())
+	assert.Equal(t, 98, it.Key())
+
+	it = m.LowerBound(99)
+
+	require.False(t, it.End())
+	assert.Equal(t, 99, it.Key())
+
+	it = m.LowerBound(100)
+
+	require.True(t, it.End())
+}
+
+func TestMapFloorValue(t *testing.T) {
+	m := New[int, int]()
+
+	_, _, ok := m.FloorValue(0)
+
+	assert.False(t, ok)
+
+	for i := range 100 {
+		m.Insert(i*2+1, i)
+	}
+
+	_, _, ok = m.FloorValue(0)
+
+	assert.False(t, ok)
+
+	for i := 1; i < 210; i++ {
+		k, v, ok := m.FloorValue(i)
+
+		require.True(t, ok)
+
+		want := min((i-1)/2, 99)
+
+		assert.Equal(t, want*2+1, k)
+		assert.Equal(t, want, v)
+	}
+}
+
+func TestMapCeilingValue(t *testing.T) {
+	m := New[int, int]()
+
+	_, _, ok := m.CeilingValue(0)
+
+	assert.False(t, ok)
+
+	for i := range 100 {
+		m.Insert(i*2+1, i)
+	}
+
+	_, _, ok = m.CeilingValue(200)
+
+	assert.False(t, ok)
+
+	for i := -10; i < 200; i++ {
+		k, v, ok := m.CeilingValue(i)
+
+		require.True(t, ok)
+
+		want := max(i/2, 0)
+
+		assert.Equal(t, want*2+1, k)
+		assert.Equal(t, want, v)
+	}
+}
+
+func TestMapFloorCeilingEntry(t *testing.T) {
+	m := New[int, int]()
+
+	_, ok := m.FloorEntry(0)
+
+	assert.False(t, ok)
+
+	_, ok = m.CeilingEntry(0)
+
+	assert.False(t, ok)
+
+	for i := range 100 {
+		m.Insert(i*2+1, i)
+	}
+
+	for i := -10; i < 210; i++ {
+		e, ok := m.FloorEntry(i)
+		k, v, wantOK := m.FloorValue(i)
+
+		assert.Equal(t, wantOK, ok)
+		assert.Equal(t, Entry[int, int]{Key: k, Value: v}, e)
+
+		e, ok = m.CeilingEntry(i)
+		k, v, wantOK = m.CeilingValue(i)
+
+		assert.Equal(t, wantOK, ok)
+		assert.Equal(t, Entry[int, int]{Key: k, Value: v}, e)
+	}
+}
+
+func TestMapNextFree(t *testing.T) {
+	m := New[uint8, int]()
+	inc := func(k uint8) uint8 { return k + 1 }
+
+	k, ok := m.NextFree(10, inc)
+
+	require.True(t, ok)
+	assert.Equal(t, uint8(10), k)
+
+	for i := range 100 {
+		m.Insert(uint8(i), i)
+	}
+
+	m.Remove(50)
+
+	k, ok = m.NextFree(0, inc)
+
+	require.True(t, ok)
+	assert.Equal(t, uint8(50), k)
+
+	k, ok = m.NextFree(51, inc)
+
+	require.True(t, ok)
+	assert.Equal(t, uint8(100), k)
+
+	k, ok = m.NextFree(200, inc)
+
+	require.True(t, ok)
+	assert.Equal(t, uint8(200), k)
+
+	k, ok = m.NextFree(1, func(k uint8) uint8 { return k + 10 })
+
+	require.True(t, ok)
+	assert.Equal(t, uint8(101), k)
+
+	for i := 100; i < 256; i++ {
+		m.Insert(uint8(i), i)
+	}
+
+	_, ok = m.NextFree(51, inc)
+
+	assert.False(t, ok)
+
+	k, ok = m.NextFree(0, inc)
+
+	require.True(t, ok)
+	assert.Equal(t, uint8(50), k)
+}
+
+func TestLowerBoundNextNode(t *testing.T) {
+	m := New[int, int]()
+
+	for i := range 48 {
+		m.Insert(i, i)
+	}
+
+	m.Remove(15)
+	m.Remove(23)
+
+	it := m.LowerBound(23)
+
+	require.False(t, it.End())
+	assert.Equal(t, 24, it.Key())
+
+	m = New[int, int]()
+
+	for i := range 1000 {
+		m.Insert(i, i)
+	}
+
+	it = m.LowerBound(1000)
+
+	require.True(t, it.End())
+}
+
+func TestMapFirstEntryLastEntry(t *testing.T) {
+	m := New[int, int]()
+
+	it, ok := m.FirstEntry()
+
+	assert.False(t, ok)
+	assert.True(t, it.End())
+
+	it, ok = m.LastEntry()
+
+	assert.False(t, ok)
+	assert.True(t, it.End())
+
+	m.Insert(5, 50)
+
+	it, ok = m.FirstEntry()
+
+	require.True(t, ok)
+	assert.Equal(t, 5, it.Key())
+	assert.Equal(t, 50, it.Value())
+
+	it, ok = m.LastEntry()
+
+	require.True(t, ok)
+	assert.Equal(t, 5, it.Key())
+	assert.Equal(t, 50, it.Value())
+	assert.True(t, it.Next().End())
+
+	for i := range 1000 {
+		m.Insert(i, i*10)
+	}
+
+	it, ok = m.FirstEntry()
+
+	require.True(t, ok)
+	assert.Equal(t, 0, it.Key())
+	assert.True(t, it.Begin())
+
+	it, ok = m.LastEntry()
+
+	require.True(t, ok)
+	assert.Equal(t, 999, it.Key())
+	assert.Equal(t, 9990, it.Value())
+	assert.True(t, it.Next().End())
+}
+
+func TestMapPeekFirstPeekLast(t *testing.T) {
+	m := New[int, string]()
+
+	_, ok := m.PeekFirst()
+
+	assert.False(t, ok)
+
+	_, ok = m.PeekLast()
+
+	assert.False(t, ok)
+
+	m.Insert(2, "bar")
+	m.Insert(1, "foo")
+	m.Insert(3, "baz")
+
+	e, ok := m.PeekFirst()
+
+	require.True(t, ok)
+	assert.Equal(t, Entry[int, string]{Key: 1, Value: "foo"}, e)
+
+	e, ok = m.PeekLast()
+
+	require.True(t, ok)
+	assert.Equal(t, Entry[int, string]{Key: 3, Value: "baz"}, e)
+	assert.Equal(t, 3, m.Len())
+}
+
+func TestMapCountFrom(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Equal(t, 0, m.CountFrom(m.Begin()))
+
+	for i := range 1000 {
+		m.Insert(i, i)
+	}
+
+	assert.Equal(t, 1000, m.CountFrom(m.Begin()))
+	assert.Equal(t, 0, m.CountFrom(m.End()))
+	assert.Equal(t, 1, m.CountFrom(m.End().Prev()))
+
+	for i := range 1000 {
+		assert.Equal(t, 1000-i, m.CountFrom(m.LowerBound(i)))
+	}
+}
+
+func TestMapKeys(t *testing.T) {
+	m := New[int, int]()
+
+	keys := []int{3, 7, 9}
+
+	for _, k := range keys {
+		m.Insert(k, k)
+	}
+
+	assert.Equal(t, keys, slices.Collect(m.Keys()))
+
+	for range m.Keys() {
+		break
+	}
+}
+
+func TestMapItems(t *testing.T) {
+	m := New[int, string]()
+
+	assert.Empty(t, slices.Collect(m.Items()))
+
+	m.Insert(3, "foo")
+	m.Insert(1, "bar")
+	m.Insert(2, "baz")
+
+	assert.Equal(t, []Entry[int, string]{
+		{Key: 1, Value: "bar"},
+		{Key: 2, Value: "baz"},
+		{Key: 3, Value: "foo"},
+	}, slices.Collect(m.Items()))
+
+	for range m.Items() {
+		break
+	}
+}
+
+func TestMapAppendKeys(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Equal(t, []int{-1}, m.AppendKeys([]int{-1}))
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
+
+	keys := m.AppendKeys(nil)
+
+	assert.Equal(t, slices.Collect(genIntSeq(1000)), keys)
+
+	if !raceEnabled {
+		assert.InDelta(t, 1, testing.AllocsPerRun(10, func() {
+			m.AppendKeys(nil)
+		}), 0)
+	}
+
+	buf := make([]int, 1, 2000)
+	keys = m.AppendKeys(buf)
+
+	assert.Equal(t, append([]int{0}, slices.Collect(genIntSeq(1000))...),
+		keys)
+	assert.Same(t, &buf[0], &keys[0])
+}
+
+func TestMapAppendValues(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Empty(t, m.AppendValues(nil))
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
+
+	values := m.AppendValues(nil)
+
+	assert.Equal(t, slices.Collect(genIntSeq(1001))[1:], values)
+
+	if !raceEnabled {
+		assert.InDelta(t, 1, testing.AllocsPerRun(10, func() {
+			m.AppendValues(nil)
+		}), 0)
+	}
+
+	buf := make([]int, 0, 1000)
+	values = m.AppendValues(buf)
+
+	assert.Equal(t, slices.Collect(genIntSeq(1001))[1:], values)
+	assert.Same(t, &buf[:1][0], &values[0])
+}
+
+func TestMapValues(t *testing.T) {
+	m := New[int, int]()
+
+	keys := []int{3, 7, 9}
+	values := []int{8, 1, 5}
+
+	for i, k := range keys {
+		m.Insert(k, values[i])
+	}
+
+	assert.Equal(t, values, slices.Collect(m.Values()))
+
+	for range m.Values() {
+		break
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	m := FromMap(map[string]int{})
+
+	verifyMap(t, m, "", "")
+
+	assert.Equal(t, 0, m.Len())
+
+	src := make(map[int]int)
+
+	for i := range 1000 {
+		src[i] = i + 1
+	}
+
+	m2 := FromMap(src)
+
+	verifyMap(t, m2, 0, 999)
+
+	assert.Equal(t, slices.Collect(genIntSeq(1000)),
+		slices.Collect(m2.Keys()))
+	assert.Equal(t, slices.Collect(genIntSeq(1001))[1:],
+		slices.Collect(m2.Values()))
+
+	m2.Insert(1000, 1001)
+	m2.Remove(0)
+
+	verifyMap(t, m2, 1, 1000)
+
+	nan := map[float64]int{
+		math.NaN():   1,
+		math.NaN():   1,
+		1:            2,
+		math.Inf(-1): 3,
+	}
+	m3 := FromMap(nan)
+
+	require.NoError(t, m3.Validate())
+	assert.Equal(t, 3, m3.Len())
+
+	v, ok := m3.Find(math.NaN())
+
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	v, ok = m3.Find(1)
+
+	require.True(t, ok)
+	assert.Equal(t, 2, v)
+}
+
+func TestToMap(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Empty(t, ToMap(m))
+
+	want := make(map[int]int)
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+		want[i] = i + 1
+	}
+
+	assert.Equal(t, want, ToMap(m))
+	assert.Equal(t, want, ToMap(FromMap(want)))
+}
+
+func TestMapNewAny(t *testing.T) {
+	m := NewAny[string, int](cmp.Compare[string])
+
+	m.Insert("foo", 1)
+	m.Insert("bar", 2)
+
+	assert.Equal(t, []string{"bar", "foo"}, slices.Collect(m.Keys()))
+}
+
+func TestMapNewAnyNilCompare(t *testing.T) {
+	assert.PanicsWithValue(t, "treemap: NewAny called with nil compare",
+		func() {
+			NewAny[string, int](nil)
+		})
+}
+
+func TestMapUseBinarySearch(t *testing.T) {
+	m := New[int, int]()
+
+	for i := range 500 {
+		m.Insert(i*2, i)
+	}
+
+	m.UseBinarySearch()
+
+	for i := range 500 {
+		m.Insert(i*2+1, i)
+	}
+
+	verifyMap(t, m, 0, 999)
+
+	assert.Equal(t, slices.Collect(genIntSeq(1000)),
+		slices.Collect(m.Keys()))
+
+	v, ok := m.Find(777)
+
+	require.True(t, ok)
+	assert.Equal(t, 388, v)
+
+	_, ok = m.Find(1000)
+
+	assert.False(t, ok)
+
+	for i := range 500 {
+		m.Remove(i * 2)
+	}
+
+	verifyMap(t, m, 1, 999)
+
+	assert.Equal(t, slices.Collect(genIntSeqStep(1, 1000, 2)),
+		slices.Collect(m.Keys()))
+}
+
+func TestMapUseNodePool(t *testing.T) {
+	m := New[int, int]()
+	m.UseNodePool()
+
+	for range 3 {
+		for i := range 1000 {
+			m.Insert(i, i+1)
+		}
+
+		verifyMap(t, m, 0, 999)
+
+		for i := 0; i < 1000; i += 2 {
+			m.Remove(i)
+		}
+
+		verifyMap(t, m, 1, 999)
+
+		assert.Equal(t, slices.Collect(genIntSeqStep(1, 1000, 2)),
+			slices.Collect(m.Keys()))
+		assert.Equal(t, slices.Collect(genIntSeqStep(2, 1001, 2)),
+			slices.Collect(m.Values()))
+
+		m.Clear()
+
+		assert.Equal(t, 0, m.Len())
+
+		verifyMap(t, m, 0, 0)
+	}
+}
+
+func TestMapUseLeafCache(t *testing.T) {
+	m := New[int, int]()
+	m.UseLeafCache()
+
+	for i := range 1000 {
+		it, _, ok := m.Insert(i*2, i)
+
+		require.False(t, it.End())
+		assert.False(t, ok)
+		assert.Equal(t, i*2, it.Key())
+		assert.Equal(t, i, it.Value())
+	}
+
+	verifyMap(t, m, 0, 1998)
+
+	for i := range 1000 {
+		it, _, ok := m.Insert(i*2+1, i)
+
+		require.False(t, it.End())
+		assert.False(t, ok)
+		assert.Equal(t, i*2+1, it.Key())
+	}
+
+	verifyMap(t, m, 0, 1999)
+
+	assert.Equal(t, slices.Collect(genIntSeq(2000)),
+		slices.Collect(m.Keys()))
+
+	it, oldValue, ok := m.Insert(1999, 1000)
+
+	require.False(t, it.End())
+	assert.True(t, ok)
+	assert.Equal(t, 999, oldValue)
+
+	for i := range 2000 {
+		v, ok := m.Find(i)
+
+		require.True(t, ok)
+
+		if i == 1999 {
+			assert.Equal(t, 1000, v)
+		} else {
+			assert.Equal(t, i/2, v)
+		}
+
+		if i%3 == 0 {
+			m.Remove(i)
+		}
+	}
+
+	verifyMap(t, m, 1, 1999)
+
+	for i := range 2000 {
+		_, ok := m.Find(i)
+
+		assert.Equal(t, i%3 != 0, ok)
+	}
+
+	m.Clear()
+
+	_, ok = m.Find(0)
+
+	assert.False(t, ok)
+
+	m.Insert(1, 1)
+
+	v, ok := m.Find(1)
+
+	require.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	m.Remove(1)
+
+	_, ok = m.Find(1)
+
+	assert.False(t, ok)
+
+	m.Insert(2, 2)
+
+	assert.Equal(t, []int{2}, slices.Collect(m.Keys()))
+}
+
+func TestMapCopyRange(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Equal(t, 0, m.CopyRange(0, 10).Len())
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	r := m.CopyRange(100, 1101)
+
+	assert.Equal(t, Collect(m.Range(100, 1101)), Collect(r.Begin().Seq()))
+
+	verifyMap(t, r, 100, 1100)
+
+	r.Insert(101, 0)
+	r.Remove(100)
+
+	_, ok := m.Find(101)
+
+	assert.False(t, ok)
+
+	_, ok = m.Find(100)
+
+	assert.True(t, ok)
+	assert.Equal(t, 0, m.CopyRange(10, 10).Len())
+	assert.Equal(t, 0, m.CopyRange(10, 0).Len())
+
+	d := NewAny[int, int](func(x, y int) int {
+		return cmp.Compare(y, x)
+	})
+
+	for i := range 100 {
+		d.Insert(i, i)
+	}
+
+	r = d.CopyRange(50, 10)
+
+	assert.Equal(t, slices.Collect(genIntSeqStep(11, 51, 1)),
+		slices.Sorted(r.Keys()))
+	assert.Equal(t, 50, r.Begin().Key())
+
+	r.Insert(5, 5)
+	r.Insert(60, 60)
+
+	assert.Equal(t, 60, r.Begin().Key())
+}
+
+func TestMapCutRange(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Equal(t, 0, m.CutRange(0, 10).Len())
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	want := Collect(m.Range(100, 1101))
+	r := m.CutRange(100, 1101)
+
+	assert.Equal(t, want, Collect(r.Begin().Seq()))
+	assert.Equal(t, 1000-len(want), m.Len())
+	assert.Empty(t, Collect(m.Range(100, 1101)))
+
+	verifyMap(t, r, 100, 1100)
+	verifyMap(t, m, 0, 1998)
+
+	assert.Equal(t, 0, m.CutRange(10, 10).Len())
+	assert.Equal(t, 0, m.CutRange(10, 0).Len())
+	assert.Equal(t, 1000-len(want), m.Len())
+
+	r = m.CutRange(-1, 3000)
+
+	assert.Equal(t, 1000-len(want), r.Len())
+	assert.Equal(t, 0, m.Len())
+
+	verifyMap(t, m, 0, 0)
+
+	m.Insert(1, 1)
+
+	assert.Equal(t, 1, m.Len())
+}
+
+func TestMapRangeInclusive(t *testing.T) {
+	m := New[string, int]()
+
+	assert.Empty(t, Collect(m.RangeInclusive("a", "z")))
+
+	for i, k := range []string{"a", "b", "ba", "bb", "c"} {
+		m.Insert(k, i)
+	}
+
+	assert.Equal(t, []string{"b", "ba", "bb"},
+		collectKeys(m.RangeInclusive("b", "bb")))
+	assert.Equal(t, []string{"b", "ba"},
+		collectKeys(m.RangeInclusive("ab", "baa")))
+	assert.Equal(t, []string{"c"}, collectKeys(m.RangeInclusive("c", "c")))
+	assert.Empty(t, collectKeys(m.RangeInclusive("c", "b")))
+	assert.Empty(t, collectKeys(m.RangeInclusive("d", "z")))
+
+	for k := range m.RangeInclusive("a", "c") {
+		if k == "b" {
+			break
+		}
+	}
+}
+
+func TestMapPartition(t *testing.T) {
+	m := New[int, int]()
+
+	yes, no := m.Partition(func(int, int) bool { return true })
+
+	assert.Equal(t, 0, yes.Len())
+	assert.Equal(t, 0, no.Len())
+
+	for i := range 1000 {
+		m.Insert(i, i*2)
+	}
+
+	yes, no = m.Partition(func(k, v int) bool {
+		assert.Equal(t, k*2, v)
+
+		return k%3 == 0
+	})
+
+	assert.Equal(t, slices.Collect(genIntSeqStep(0, 1000, 3)),
+		slices.Collect(yes.Keys()))
+	assert.Equal(t, 666, no.Len())
+	assert.Equal(t, 1000, m.Len())
+
+	for k := range no.Keys() {
+		assert.NotZero(t, k%3)
+	}
+
+	verifyMap(t, yes, 0, 999)
+	verifyMap(t, no, 1, 998)
+	verifyMap(t, m, 0, 999)
+}
+
+func TestMapReorder(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Equal(t, 0, m.Reorder(cmp.Compare[int]).Len())
+
+	for i := range 1000 {
+		m.Insert(i, i*2)
+	}
+
+	r := m.Reorder(func(x, y int) int {
+		return cmp.Compare(y, x)
+	})
+
+	keys := slices.Collect(genIntSeqStep(0, 1000, 1))
+	slices.Reverse(keys)
+
+	assert.Equal(t, keys, slices.Collect(r.Keys()))
+	assert.Equal(t, 1000, m.Len())
+	assert.Equal(t, 0, m.Begin().Key())
+
+	r.Insert(1000, 0)
+
+	assert.Equal(t, 1000, r.Begin().Key())
+
+	require.NoError(t, r.Validate())
+
+	r = m.Reorder(func(x, y int) int {
+		return cmp.Compare(x%10, y%10)
+	})
+
+	assert.Equal(t, slices.Collect(genIntSeqStep(990, 1000, 1)),
+		slices.Collect(r.Keys()))
+}
+
+func TestRangeReduce(t *testing.T) {
+	sum := func(acc, _, v int) int {
+		return acc + v
+	}
+
+	m := New[int, int]()
+
+	assert.Equal(t, 7, RangeReduce(m, 0, 100, 7, sum))
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	assert.Equal(t, (50+149)*100/2, RangeReduce(m, 100, 300, 0, sum))
+	assert.Equal(t, (50+149)*100/2, RangeReduce(m, 99, 299, 0, sum))
+	assert.Equal(t, 999*1000/2+1, RangeReduce(m, -1, 5000, 1, sum))
+	assert.Equal(t, 1, RangeReduce(m, 100, 100, 1, sum))
+	assert.Equal(t, 1, RangeReduce(m, 100, 0, 1, sum))
+	assert.Equal(t, 0, RangeReduce(m, 5000, 6000, 0, sum))
+	assert.Equal(t, []int{10, 12},
+		RangeReduce(m, 10, 14, nil, func(acc []int, k, _ int) []int {
+			return append(acc, k)
+		}))
+}
+
+func TestGroupBy(t *testing.T) {
+	m := New[int, int]()
+
+	for range GroupBy(m, func(int) int { return 0 }) {
+		assert.Fail(t, "empty Map must yield no group")
+	}
+
+	for i := range 1000 {
+		m.Insert(i, i*2)
+	}
+
+	var (
+		buckets []int
+		sizes   []int
+	)
+
+	for b, group := range GroupBy(m, func(k int) int { return k / 100 }) {
+		buckets = append(buckets, b)
+		n := 0
+
+		for k, v := range group {
+			assert.Equal(t, b, k/100)
+			assert.Equal(t, k*2, v)
+
+			n++
+		}
+
+		sizes = append(sizes, n)
+	}
+
+	assert.Equal(t, slices.Collect(genIntSeq(10)), buckets)
+	assert.Equal(t, slices.Repeat([]int{100}, 10), sizes)
+
+	var flags []bool
+
+	for b := range GroupBy(m, func(k int) bool { return k%300 < 150 }) {
+		flags = append(flags, b)
+	}
+
+	assert.Equal(t, []bool{true, false, true, false, true, false, true},
+		flags)
+
+	m = New[int, int]()
+
+	m.Insert(1, 1)
+
+	for b, group := range GroupBy(m, func(int) string { return "x" }) {
+		assert.Equal(t, "x", b)
+		assert.Equal(t, []int{1}, collectKeys(group))
+	}
+
+	m.Insert(2, 2)
+
+	n := 0
+
+	for range GroupBy(m, strconv.Itoa) {
+		n++
+
+		break
+	}
+
+	assert.Equal(t, 1, n)
+}
+
+func TestMapRangeIter(t *testing.T) {
+	m := New[int, int]()
+
+	begin, end := m.RangeIter(0, 10)
+
+	assert.True(t, begin.Equal(end))
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	for _, tc := range []struct {
+		lo, hi int
+	}{
+		{lo: 100, hi: 299},
+		{lo: 101, hi: 300},
+		{lo: -10, hi: 10},
+		{lo: 1990, hi: 5000},
+		{lo: 10, hi: 10},
+		{lo: 10, hi: 0},
+		{lo: 5000, hi: 6000},
+	} {
+		begin, end := m.RangeIter(tc.lo, tc.hi)
+
+		var keys []int
+
+		for it := begin; !it.Equal(end); it = it.Next() {
+			keys = append(keys, it.Key())
+		}
+
+		assert.Equal(t, collectKeys(m.Range(tc.lo, tc.hi)), keys,
+			"lo=%d hi=%d", tc.lo, tc.hi)
+	}
+}
+
+func TestMapRange(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Empty(t, Collect(m.Range(0, 10)))
+
+	for i := range 500 {
+		m.Insert(i*2, i)
+	}
+
+	keys := slices.Collect(genIntSeqStep(100, 300, 2))
+
+	var got []int
+
+	for k, v := range m.Range(99, 300) {
+		assert.Equal(t, k/2, v)
+
+		got = append(got, k)
+	}
+
+	assert.Equal(t, keys, got)
+	assert.Equal(t, keys, collectKeys(m.Range(100, 299)))
+	assert.Equal(t, []int{998}, collectKeys(m.Range(998, 2000)))
+	assert.Empty(t, Collect(m.Range(300, 300)))
+	assert.Empty(t, Collect(m.Range(300, 100)))
+	assert.Empty(t, Collect(m.Range(1000, 2000)))
+	assert.Empty(t, Collect(m.Range(-10, 0)))
+
+	for range m.Range(0, 1000) {
+		break
+	}
+}
+
+func TestMapRangeBackward(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Empty(t, Collect(m.RangeBackward(0, 10)))
+
+	for i := range 500 {
+		m.Insert(i*2, i)
+	}
+
+	keys := slices.Collect(genIntSeqStep(100, 300, 2))
+	slices.Reverse(keys)
+
+	var got []int
+
+	for k, v := range m.RangeBackward(99, 300) {
+		assert.Equal(t, k/2, v)
+
+		got = append(got, k)
+	}
+
+	assert.Equal(t, keys, got)
+
+	got = collectKeys(m.RangeBackward(-10, 2000))
+
+	assert.Len(t, got, 500)
+	assert.Equal(t, 998, got[0])
+	assert.Equal(t, 0, got[499])
+	assert.Empty(t, Collect(m.RangeBackward(300, 300)))
+	assert.Empty(t, Collect(m.RangeBackward(300, 100)))
+	assert.Empty(t, Collect(m.RangeBackward(1000, 2000)))
+	assert.Empty(t, Collect(m.RangeBackward(-10, 0)))
+
+	for range m.RangeBackward(0, 1000) {
+		break
+	}
+}
+
+func TestMapSeqRef(t *testing.T) {
+	type large struct {
+		a [16]int
+	}
+
+	m := New[int, large]()
+
+	for range m.SeqRef() {
+		assert.Fail(t, "must not be called")
+	}
+
+	for i := range 1000 {
+		m.Insert(i, large{a: [16]int{i}})
+	}
+
+	var keys []int
+
+	for k, v := range m.SeqRef() {
+		assert.Equal(t, k, v.a[0])
+
+		v.a[1] = k * 2
+
+		keys = append(keys, k)
+	}
+
+	assert.Equal(t, slices.Collect(genIntSeqStep(0, 1000, 1)), keys)
+
+	for k, v := range m.Begin().Seq() {
+		assert.Equal(t, k*2, v.a[1])
+	}
+
+	for k := range m.SeqRef() {
+		if k == 10 {
+			break
+		}
+	}
+}
+
+func TestMapEnumerate(t *testing.T) {
+	m := New[int, int]()
+
+	for range m.Enumerate() {
+		assert.Fail(t, "must not be called")
+	}
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	n := 0
+
+	for i, e := range m.Enumerate() {
+		assert.Equal(t, n, i)
+		assert.Equal(t, Entry[int, int]{Key: i * 2, Value: i}, e)
+
+		n++
+	}
+
+	assert.Equal(t, 1000, n)
+
+	for i := range m.Enumerate() {
+		if i == 10 {
+			break
+		}
+	}
+}
+
+func TestMapChunks(t *testing.T) {
+	m := New[int, int]()
+
+	for range m.Chunks() {
+		assert.Fail(t, "must not be called")
+	}
+
+	for i := range 1000 {
+		m.Insert(i, i*2)
+	}
+
+	var keys []int
+
+	chunks := 0
+
+	for ks, vs := range m.Chunks() {
+		require.Len(t, vs, len(ks))
+		assert.Equal(t, len(ks), cap(ks))
+
+		for i, k := range ks {
+			assert.Equal(t, k*2, vs[i])
+		}
+
+		keys = append(keys, ks...)
+		chunks++
+	}
+
+	_, leaves := m.countNodes()
+
+	assert.Equal(t, leaves, chunks)
+	assert.Equal(t, slices.Collect(genIntSeqStep(0, 1000, 1)), keys)
+
+	for range m.Chunks() {
+		break
+	}
+}
+
+func TestMapScan(t *testing.T) {
+	m := New[int, int]()
+
+	m.Scan(func(*int, *int) bool {
+		assert.Fail(t, "fn must not be called")
+
+		return true
+	})
+
+	for i := range 1000 {
+		m.Insert(i, i)
+	}
+
+	var keys []int
+
+	m.Scan(func(key, value *int) bool {
+		keys = append(keys, *key)
+		*value = -*key
+
+		return true
+	})
+
+	assert.Equal(t, slices.Collect(genIntSeqStep(0, 1000, 1)), keys)
+
+	for k, v := range m.Begin().Seq() {
+		assert.Equal(t, -k, v)
+	}
+
+	n := 0
+
+	m.Scan(func(key, _ *int) bool {
+		n++
+
+		return *key < 99
+	})
+
+	assert.Equal(t, 100, n)
+}
+
+func TestMapWalkLeaves(t *testing.T) {
+	m := New[int, int]()
+
+	m.WalkLeaves(func([]int, []int) bool {
+		assert.Fail(t, "fn must not be called for empty m")
+
+		return true
+	})
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
+
+	var keys, values []int
+
+	leaves := 0
+
+	m.WalkLeaves(func(k, v []int) bool {
+		require.Len(t, v, len(k))
+		assert.NotEmpty(t, k)
+
+		keys = append(keys, k...)
+		values = append(values, v...)
+		leaves++
+
+		return true
+	})
+
+	assert.Greater(t, leaves, 1)
+	assert.Equal(t, slices.Collect(m.Keys()), keys)
+	assert.Equal(t, slices.Collect(m.Values()), values)
+
+	n := 0
+
+	m.WalkLeaves(func([]int, []int) bool {
+		n++
+
+		return false
+	})
+
+	assert.Equal(t, 1, n)
+}
+
+func TestMapSnapshot(t *testing.T) {
+	m := New[int, int]()
+
+	for i := range 100 {
+		m.Insert(i, i+1)
+	}
+
+	snap := m.Snapshot()
+
+	for i := range 50 {
+		m.Remove(i)
+	}
+
+	m.Insert(1000, 1001)
+
+	n := 0
+
+	for k, v := range snap {
+		assert.Equal(t, n, k)
+		assert.Equal(t, n+1, v)
+
+		if k%10 == 0 {
+			m.Remove(k + 50)
+		}
+
+		n++
+	}
+
+	assert.Equal(t, 100, n)
+
+	for range snap {
+		break
+	}
+}
+
+func TestMapUseLazyRebalance(t *testing.T) {
+	m := New[int, int]()
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
+
+	m.UseLazyRebalance()
+
+	for i := range 1000 {
+		if i%8 != 0 {
+			m.Remove(i)
+		}
+	}
+
+	verifyMap(t, m, 0, 999)
+
+	assert.Equal(t, slices.Collect(genIntSeqStep(0, 1000, 8)),
+		slices.Collect(m.Keys()))
+
+	leaves := 0
+
+	for tnode := m.front; tnode != nil; tnode = tnode.next {
+		leaves++
+
+		assert.Less(t, tnode.n, minNodes)
+	}
+
+	assert.Greater(t, leaves, 1)
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
+
+	verifyMap(t, m, 0, 999)
+
+	it := m.Begin()
+	for !it.End() {
+		it = m.RemoveIter(it)
+	}
+
+	verifyMap(t, m, 0, 0)
+
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestMapEqualSlice(t *testing.T) {
+	m := New[int, string]()
+	eq := func(a, b string) bool { return a == b }
+
+	assert.True(t, m.EqualSlice(nil, nil, eq))
+	assert.False(t, m.EqualSlice([]int{1}, []string{"1"}, eq))
+
+	var (
+		keys   []int
+		values []string
+	)
+
+	for i := range 1000 {
+		m.Insert(i, strconv.Itoa(i))
+
+		keys = append(keys, i)
+		values = append(values, strconv.Itoa(i))
+	}
+
+	assert.True(t, m.EqualSlice(keys, values, eq))
+	assert.False(t, m.EqualSlice(keys[1:], values[1:], eq))
+	assert.False(t, m.EqualSlice(keys, values[1:], eq))
+
+	keys[500] = 10000
+
+	assert.False(t, m.EqualSlice(keys, values, eq))
+
+	keys[500] = 500
+	values[999] = "x"
+
+	assert.False(t, m.EqualSlice(keys, values, eq))
+	assert.True(t, m.EqualSlice(keys, values, func(a, b string) bool {
+		return a == b || b == "x"
+	}))
+}
+
+func TestMapString(t *testing.T) {
+	m := New[int, string]()
+
+	assert.Equal(t, "Map[]", m.String())
+
+	m.Insert(1, "foo")
+	m.Insert(2, "bar")
+
+	assert.Equal(t, "Map[1:foo 2:bar]", m.String())
+
+	// A nil pointer whose element implements encoding.TextMarshaler
+	// with the value receiver must not panic.
+	tm := New[int, *time.Time]()
+
+	tm.Insert(1, nil)
+
+	assert.Equal(t, "Map[1:<nil>]", tm.String())
+}
+
+func TestMapStringN(t *testing.T) {
+	m := New[int, string]()
+
+	assert.Equal(t, "Map[]", m.StringN(0))
+	assert.Equal(t, "Map[]", m.StringN(10))
+
+	m.Insert(1, "foo")
+	m.Insert(2, "bar")
+	m.Insert(3, "baz")
+
+	assert.Equal(t, "Map[...(3 more)]", m.StringN(0))
+	assert.Equal(t, "Map[...(3 more)]", m.StringN(-1))
+	assert.Equal(t, "Map[1:foo 2:bar ...(1 more)]", m.StringN(2))
+	assert.Equal(t, "Map[1:foo 2:bar 3:baz]", m.StringN(3))
+	assert.Equal(t, "Map[1:foo 2:bar 3:baz]", m.StringN(100))
+}
+
+func TestMapStringRange(t *testing.T) {
+	m := New[int, string]()
+
+	assert.Equal(t, "Map[]", m.StringRange(0, 10))
+
+	for i := range 100 {
+		m.Insert(i, strconv.Itoa(i*2))
+	}
+
+	assert.Equal(t, "Map[10:20 11:22 12:24]", m.StringRange(10, 13))
+	assert.Equal(t, "Map[98:196 99:198]", m.StringRange(98, 1000))
+	assert.Equal(t, "Map[0:0]", m.StringRange(-5, 1))
+	assert.Equal(t, "Map[]", m.StringRange(13, 10))
+	assert.Equal(t, "Map[]", m.StringRange(10, 10))
+	assert.Equal(t, "Map[]", m.StringRange(200, 300))
+}
+
+func TestMapInsertRemoveSplitExtendKey(t *testing.T) {
+	m := New[uint64, int]()
+
+	m.Insert(9631484016779065335, 0)
 	m.Insert(17868022691004923650, 0)
 	m.Remove(39359)
 	m.Insert(13833645161281420026, 0)
@@ -747,7 +2862,676 @@ func TestMapInsertRemoveSplitExtendKey(t *testing.T) {
 	m.Insert(2680373613224892825, 0)
 	m.Insert(11068046444225730969, 0)
 
-	verifyMap(t, m, 0, math.MaxUint64)
+	verifyMap(t, m, 0, math.MaxUint64)
+}
+
+func TestMapCompact(t *testing.T) {
+	m := New[int, int]()
+
+	m.Compact()
+
+	verifyMap(t, m, 0, 0)
+
+	for i := range 10000 {
+		m.Insert(i, i+1)
+	}
+
+	for i := range 10000 {
+		if i%10 != 0 {
+			m.Remove(i)
+		}
+	}
+
+	height := m.height
+
+	m.Compact()
+
+	verifyMap(t, m, 0, 9990)
+
+	assert.Less(t, m.height, height)
+	assert.Equal(t, slices.Collect(genIntSeqStep(0, 10000, 10)),
+		slices.Collect(m.Keys()))
+	assert.Equal(t, slices.Collect(genIntSeqStep(1, 10001, 10)),
+		slices.Collect(m.Values()))
+
+	leaves := 0
+
+	for tnode := m.front; tnode != nil; tnode = tnode.next {
+		leaves++
+	}
+
+	assert.Equal(t, (m.Len()+maxNodes-1)/maxNodes, leaves)
+
+	for i := range 10000 {
+		m.Insert(i, i+1)
+	}
+
+	verifyMap(t, m, 0, 9999)
+
+	assert.Equal(t, 10000, m.Len())
+}
+
+func TestMapByteSize(t *testing.T) {
+	m := New[int64, int64]()
+
+	mapSize := int(unsafe.Sizeof(*m))
+	leafSize := int(unsafe.Sizeof(leafNode[int64, int64]{}))
+	internalSize := int(unsafe.Sizeof(internalNode[int64, int64]{}))
+
+	if strconv.IntSize == 64 {
+		// A leaf node has 2 pointers, 32 keys, 32 values, and the
+		// count.  An internal node has 32 interface values, 32 keys,
+		// and the count.
+		assert.Equal(t, 2*8+32*8+32*8+8, leafSize)
+		assert.Equal(t, 32*16+32*8+8, internalSize)
+	}
+
+	assert.Equal(t, mapSize+leafSize, m.ByteSize())
+
+	m.Insert(-1, 0)
+
+	assert.Equal(t, mapSize+leafSize, m.ByteSize())
+
+	m.Remove(-1)
+
+	for i := range maxNodes {
+		m.Insert(int64(i), 0)
+	}
+
+	assert.Equal(t, mapSize+leafSize, m.ByteSize())
+
+	m.Insert(maxNodes, 0)
+
+	assert.Equal(t, mapSize+2*leafSize+internalSize, m.ByteSize())
+
+	// Inserting the increasing keys splits the last leaf node into 16
+	// and 16 items each time it overflows, so 1000 keys occupy 61
+	// leaf nodes of 16 items and the last one of 24 items.  The root
+	// overflows at 32 children and is split into 2 internal nodes of
+	// 16 children, and the second one of them is split again.  The
+	// internal nodes at height 1 have 16, 16, and 30 children under
+	// the new root.
+	for i := range 1000 {
+		m.Insert(int64(i), 0)
+	}
+
+	assert.Equal(t, mapSize+62*leafSize+4*internalSize, m.ByteSize())
+
+	// Compact packs 1000 items into 31 leaf nodes of 32 items and 8
+	// items, and the last two of them are balanced to 20 items each.
+	// All 32 leaf nodes fit in the root.
+	m.Compact()
+
+	assert.Equal(t, mapSize+32*leafSize+internalSize, m.ByteSize())
+}
+
+func TestMapMergeSeq(t *testing.T) {
+	sum := func(existing, incoming int) int {
+		return existing + incoming
+	}
+
+	m := New[int, int]()
+
+	m.MergeSeq(New[int, int]().Begin().Seq(), sum)
+
+	assert.Equal(t, 0, m.Len())
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	src := New[int, int]()
+
+	for i := range 1000 {
+		src.Insert(i*3, 1)
+	}
+
+	m.MergeSeq(src.Begin().Seq(), sum)
+
+	assert.Equal(t, 1000+1000-334, m.Len())
+
+	verifyMap(t, m, 0, 2997)
+
+	for k, v := range m.Begin().Seq() {
+		switch {
+		case k%6 == 0 && k < 2000:
+			assert.Equal(t, k/2+1, v)
+		case k%3 == 0:
+			assert.Equal(t, 1, v)
+		default:
+			assert.Equal(t, k/2, v)
+		}
+	}
+
+	m.Clear()
+
+	m.Insert(1, 1)
+	m.Insert(5, 5)
+	m.MergeSeq(func(yield func(int, int) bool) {
+		for _, k := range []int{3, 6, 5, 0, 6, 2} {
+			if !yield(k, 10) {
+				return
+			}
+		}
+	}, sum)
+
+	assert.Equal(t, []int{0, 1, 2, 3, 5, 6}, slices.Collect(m.Keys()))
+	assert.Equal(t, []int{10, 1, 10, 10, 15, 20}, slices.Collect(m.Values()))
+
+	verifyMap(t, m, 0, 6)
+}
+
+func TestMapIndexOf(t *testing.T) {
+	m := New[int, int]()
+
+	_, ok := m.IndexOf(0)
+
+	assert.False(t, ok)
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	for i := range 2000 {
+		idx, ok := m.IndexOf(i)
+		if i%2 == 1 {
+			assert.False(t, ok)
+
+			continue
+		}
+
+		require.True(t, ok)
+		assert.Equal(t, i/2, idx)
+
+		k, _ := m.KeyAt(idx)
+
+		assert.Equal(t, i, k)
+	}
+}
+
+func TestMapKeyAt(t *testing.T) {
+	m := New[int, int]()
+
+	_, ok := m.KeyAt(0)
+
+	assert.False(t, ok)
+	assert.False(t, m.SetValueAt(0, 1))
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	for i := range 1000 {
+		k, ok := m.KeyAt(i)
+
+		require.True(t, ok)
+		assert.Equal(t, i*2, k)
+		assert.True(t, m.SetValueAt(i, -i))
+	}
+
+	for k, v := range m.Begin().Seq() {
+		assert.Equal(t, -k/2, v)
+	}
+
+	_, ok = m.KeyAt(-1)
+
+	assert.False(t, ok)
+
+	_, ok = m.KeyAt(1000)
+
+	assert.False(t, ok)
+	assert.False(t, m.SetValueAt(-1, 1))
+	assert.False(t, m.SetValueAt(1000, 1))
+}
+
+func TestMapSample(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	m := New[int, int]()
+
+	assert.Empty(t, collectKeys(m.Sample(3, rng)))
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
+
+	assert.Empty(t, collectKeys(m.Sample(0, rng)))
+	assert.Empty(t, collectKeys(m.Sample(-1, rng)))
+	assert.Equal(t, slices.Collect(m.Keys()),
+		collectKeys(m.Sample(2000, rng)))
+
+	for _, k := range []int{1, 10, 500, 999} {
+		var keys []int
+
+		for key, value := range m.Sample(k, rng) {
+			assert.Equal(t, key+1, value)
+
+			keys = append(keys, key)
+		}
+
+		assert.Len(t, keys, k)
+		assert.True(t, slices.IsSorted(keys))
+		assert.Len(t, slices.Compact(keys), k)
+	}
+
+	keys := collectKeys(m.Sample(10, rng))
+
+	assert.Len(t, keys, 10)
+	assert.NotEqual(t, keys, collectKeys(m.Sample(10, rng)))
+
+	m = New[int, int]()
+
+	for i := range 5 {
+		m.Insert(i, i)
+	}
+
+	const trials = 10000
+
+	counts := make([]int, 5)
+
+	for range trials {
+		for key := range m.Sample(2, rng) {
+			counts[key]++
+		}
+	}
+
+	for _, c := range counts {
+		assert.InDelta(t, trials*2/5, c, trials/20)
+	}
+}
+
+func TestMapReplaceAll(t *testing.T) {
+	m := New[int, int]()
+
+	for i := range 1000 {
+		m.Insert(i, i)
+	}
+
+	require.Error(t, m.ReplaceAll([]int{1, 2}, []int{1}))
+	assert.Equal(t, 1000, m.Len())
+
+	require.NoError(t, m.ReplaceAll(nil, nil))
+	assert.Equal(t, 0, m.Len())
+
+	verifyMap(t, m, 0, 0)
+
+	keys := slices.Collect(genIntSeqStep(0, 2000, 1))
+
+	require.NoError(t, m.ReplaceAll(keys, keys))
+	assert.Equal(t, keys, slices.Collect(m.Keys()))
+	assert.Equal(t, keys, slices.Collect(m.Values()))
+
+	verifyMap(t, m, 0, 1999)
+
+	require.NoError(t, m.ReplaceAll([]int{5, 1, 3, 1, 5, 5},
+		[]int{1, 2, 3, 4, 5, 6}))
+	assert.Equal(t, []int{1, 3, 5}, slices.Collect(m.Keys()))
+	assert.Equal(t, []int{4, 3, 6}, slices.Collect(m.Values()))
+
+	verifyMap(t, m, 1, 5)
+}
+
+func TestMapNaN(t *testing.T) {
+	nan := math.NaN()
+	m := New[float64, int]()
+
+	m.Insert(nan, 1)
+	m.Insert(1, 2)
+	m.Insert(nan, 3)
+	m.Insert(math.Inf(-1), 4)
+
+	assert.Equal(t, 3, m.Len())
+
+	keys := slices.Collect(m.Keys())
+
+	require.Len(t, keys, 3)
+	assert.True(t, math.IsNaN(keys[0]))
+	assert.Equal(t, []float64{math.Inf(-1), 1}, keys[1:])
+
+	v, ok := m.Find(nan)
+
+	require.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	for i := range 1000 {
+		m.Insert(float64(i), i)
+		m.Insert(nan, i)
+	}
+
+	assert.Equal(t, 1002, m.Len())
+	require.NoError(t, m.Validate())
+
+	v, ok = m.Remove(nan)
+
+	require.True(t, ok)
+	assert.Equal(t, 999, v)
+
+	_, ok = m.Find(nan)
+
+	assert.False(t, ok)
+	assert.Equal(t, 1001, m.Len())
+	require.NoError(t, m.Validate())
+}
+
+func FuzzMapFloat(f *testing.F) {
+	f.Add(0.0, math.NaN(), math.NaN(), 1.0)
+	f.Add(math.NaN(), math.Inf(1), math.Inf(-1), math.NaN())
+	f.Add(math.Copysign(0, -1), 0.0, 1.5, -1.5)
+
+	f.Fuzz(func(t *testing.T, a, b, c, d float64) {
+		m := New[float64, int]()
+		ref := make(map[float64]int)
+
+		for i := range 100 {
+			for j, k := range []float64{a, b, c, d} {
+				k += float64(i % (j + 1))
+				m.Insert(k, i)
+				ref[k] = i
+			}
+		}
+
+		require.NoError(t, m.Validate())
+
+		// NaN is not equal to itself in Go maps.
+		n := 0
+
+		for k := range ref {
+			if !math.IsNaN(k) {
+				n++
+			}
+		}
+
+		_, ok := m.Find(math.NaN())
+		if ok {
+			n++
+		}
+
+		assert.Equal(t, n, m.Len())
+		assert.True(t, slices.IsSortedFunc(slices.Collect(m.Keys()),
+			cmp.Compare[float64]))
+	})
+}
+
+func TestDeltaKeys(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Empty(t, slices.Collect(DeltaKeys(m)))
+
+	for _, k := range []int{10, -5, 3, 100, 101} {
+		m.Insert(k, 0)
+	}
+
+	assert.Equal(t, []int{-5, 8, 7, 90, 1}, slices.Collect(DeltaKeys(m)))
+
+	m8 := New[int8, int]()
+
+	m8.Insert(math.MinInt8, 0)
+	m8.Insert(math.MaxInt8, 0)
+
+	var (
+		key  int8
+		keys []int8
+	)
+
+	for d := range DeltaKeys(m8) {
+		key += d
+		keys = append(keys, key)
+	}
+
+	assert.Equal(t, slices.Collect(m8.Keys()), keys)
+
+	for d := range DeltaKeys(m) {
+		assert.Equal(t, -5, d)
+
+		break
+	}
+}
+
+func TestMapSetSearch(t *testing.T) {
+	m := New[int, int]()
+
+	n := 0
+
+	m.SetSearch(func(keys []int, target int) (int, bool) {
+		n++
+
+		return slices.BinarySearch(keys, target)
+	})
+
+	for i := range 1000 {
+		m.Insert(i*2, i)
+	}
+
+	assert.Positive(t, n)
+
+	for i := range 2000 {
+		v, ok := m.Find(i)
+
+		assert.Equal(t, i%2 == 0, ok)
+		assert.Equal(t, (1-i%2)*i/2, v)
+	}
+
+	verifyMap(t, m, 0, 1998)
+
+	assert.PanicsWithValue(t, "treemap: SetSearch called with nil search",
+		func() {
+			m.SetSearch(nil)
+		})
+}
+
+func TestMapEmpty(t *testing.T) {
+	m := New[int, int]()
+
+	assert.True(t, m.Empty())
+
+	m.Insert(1, 1)
+
+	assert.False(t, m.Empty())
+
+	m.Remove(1)
+
+	assert.True(t, m.Empty())
+}
+
+func TestMapReversedOrder(t *testing.T) {
+	m := NewAny[int, int](func(x, y int) int {
+		return cmp.Compare(y, x)
+	})
+
+	for i := range 1000 {
+		k := i * 7919 % 1000
+		m.Insert(k, k)
+	}
+
+	require.NoError(t, m.Validate())
+	assert.True(t, slices.IsSortedFunc(slices.Collect(m.Keys()),
+		func(x, y int) int { return cmp.Compare(y, x) }))
+
+	assert.Equal(t, 999, m.Begin().Key())
+	assert.Equal(t, 0, m.End().Prev().Key())
+	assert.Equal(t, 0, m.RBegin().Key())
+
+	e, ok := m.PeekFirst()
+
+	require.True(t, ok)
+	assert.Equal(t, 999, e.Key)
+
+	e, ok = m.PeekLast()
+
+	require.True(t, ok)
+	assert.Equal(t, 0, e.Key)
+
+	it, ok := m.FirstEntry()
+
+	require.True(t, ok)
+	assert.Equal(t, 999, it.Key())
+
+	it, ok = m.LastEntry()
+
+	require.True(t, ok)
+	assert.Equal(t, 0, it.Key())
+
+	k, _, ok := m.CeilingValue(1500)
+
+	require.True(t, ok)
+	assert.Equal(t, 999, k)
+
+	_, _, ok = m.FloorValue(1500)
+
+	assert.False(t, ok)
+	assert.Equal(t, []int{12, 11}, collectKeys(m.Range(12, 10)))
+
+	require.True(t, m.RemoveMin())
+	require.True(t, m.RemoveMax())
+
+	assert.Equal(t, 998, m.Begin().Key())
+	assert.Equal(t, 1, m.End().Prev().Key())
+	require.NoError(t, m.Validate())
+}
+
+func TestMapComparator(t *testing.T) {
+	m := New[int, int]()
+	compare := m.Comparator()
+
+	assert.Negative(t, compare(1, 2))
+	assert.Zero(t, compare(2, 2))
+	assert.Positive(t, compare(3, 2))
+
+	r := NewAny[int, int](func(x, y int) int {
+		return cmp.Compare(y, x)
+	})
+	compare = r.Comparator()
+
+	assert.Positive(t, compare(1, 2))
+	assert.Negative(t, compare(3, 2))
+}
+
+func TestMapComparePanic(t *testing.T) {
+	// countdown is the number of the comparisons until compare
+	// panics.  compare does not panic if it is negative.
+	countdown := -1
+
+	m := NewAny[int, int](func(x, y int) int {
+		if countdown == 0 {
+			panic("compare")
+		}
+
+		if countdown > 0 {
+			countdown--
+		}
+
+		return cmp.Compare(x, y)
+	})
+	ref := make(map[int]int)
+
+	for i := range 2000 {
+		m.Insert(i*2, i)
+		ref[i*2] = i
+	}
+
+	try := func(fn func()) (panicked bool) {
+		defer func() {
+			if r := recover(); r != nil {
+				assert.Equal(t, "compare", r)
+
+				panicked = true
+			}
+
+			countdown = -1
+		}()
+
+		fn()
+
+		return false
+	}
+
+	for i := range 1000 {
+		key := (i * 7919) % 4000
+
+		for n := range 40 {
+			countdown = n
+
+			if try(func() { m.Insert(key, -key) }) {
+				continue
+			}
+
+			ref[key] = -key
+
+			break
+		}
+
+		require.NoError(t, m.Validate())
+
+		key = (i * 104729) % 4000
+
+		for n := range 40 {
+			countdown = n
+
+			if try(func() { m.Remove(key) }) {
+				continue
+			}
+
+			delete(ref, key)
+
+			break
+		}
+
+		require.NoError(t, m.Validate())
+
+		if i%16 == 0 {
+			require.Equal(t, ref, ToMap(m))
+		}
+	}
+
+	assert.Equal(t, ref, ToMap(m))
+}
+
+func TestMapStats(t *testing.T) {
+	m := New[int, int]()
+
+	assert.Equal(t, Stats{
+		Leaves:   1,
+		Capacity: maxNodes,
+	}, m.Stats())
+
+	for i := range maxNodes + 1 {
+		m.Insert(i, 0)
+	}
+
+	assert.Equal(t, Stats{
+		Leaves:    2,
+		Internals: 1,
+		Height:    1,
+		Capacity:  3 * maxNodes,
+		Used:      maxNodes + 3,
+		FillRatio: float64(maxNodes+3) / float64(3*maxNodes),
+	}, m.Stats())
+
+	// See TestMapByteSize for the shape of the tree.  Used counts
+	// 1000 items, 62 children of the internal nodes at height 1, and 3
+	// children of the root.
+	for i := range 1000 {
+		m.Insert(i, 0)
+	}
+
+	assert.Equal(t, Stats{
+		Leaves:    62,
+		Internals: 4,
+		Height:    2,
+		Capacity:  66 * maxNodes,
+		Used:      1000 + 62 + 3,
+		FillRatio: float64(1000+62+3) / float64(66*maxNodes),
+	}, m.Stats())
+
+	m.Compact()
+
+	assert.Equal(t, Stats{
+		Leaves:    32,
+		Internals: 1,
+		Height:    1,
+		Capacity:  33 * maxNodes,
+		Used:      1000 + 32,
+		FillRatio: float64(1000+32) / float64(33*maxNodes),
+	}, m.Stats())
 }
 
 func TestMapClear(t *testing.T) {
@@ -776,6 +3560,40 @@ func TestMapClear(t *testing.T) {
 	verifyMap(t, m, 1, 2)
 }
 
+func TestMapClearZeroing(t *testing.T) {
+	for _, usePool := range []bool{false, true} {
+		m := New[int, *int]()
+		if usePool {
+			m.UseNodePool()
+		}
+
+		m.ClearZeroing()
+
+		verifyMap(t, m, 0, 0)
+
+		for i := range 1000 {
+			m.Insert(i, &i)
+		}
+
+		first, last := m.Begin(), m.End().Prev()
+
+		m.ClearZeroing()
+
+		verifyMap(t, m, 0, 0)
+
+		assert.Equal(t, 0, m.Len())
+
+		for _, it := range []Iterator[int, *int]{first, last} {
+			assert.Equal(t, [maxNodes]*int{}, it.node.values)
+			assert.Equal(t, [maxNodes]int{}, it.node.keys)
+		}
+
+		m.Insert(1, nil)
+
+		assert.Equal(t, 1, m.Len())
+	}
+}
+
 func TestMapClear1000(t *testing.T) {
 	m := New[int, int]()
 
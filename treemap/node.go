@@ -27,6 +27,9 @@ const (
 	keyDegr  = 16
 	maxNodes = 2 * keyDegr
 	minNodes = keyDegr
+	// lazyMinNodes is the minimum number of items or children that a
+	// non-root node must have if lazy rebalancing is enabled.
+	lazyMinNodes = keyDegr / 4
 )
 
 type node[Key, Value any] interface {
@@ -66,9 +69,9 @@ func (inode *internalNode[Key, Value]) IsFull() bool {
 }
 
 func (inode *internalNode[Key, Value]) Split(
-	*Map[Key, Value],
+	m *Map[Key, Value],
 ) node[Key, Value] {
-	rnode := &internalNode[Key, Value]{}
+	rnode := m.newInternalNode()
 
 	n := inode.n
 	rnode.n = inode.n / 2
@@ -86,6 +89,8 @@ func (inode *internalNode[Key, Value]) SplitAt(idx int, m *Map[Key, Value]) {
 	lnode := inode.nodes[idx]
 	rnode := lnode.Split(m)
 
+	m.splits++
+
 	copy(inode.nodes[idx+2:], inode.nodes[idx+1:inode.n])
 	copy(inode.keys[idx+1:], inode.keys[idx:inode.n])
 
@@ -172,9 +177,8 @@ func (tnode *leafNode[Key, Value]) IsFull() bool {
 }
 
 func (tnode *leafNode[Key, Value]) Split(m *Map[Key, Value]) node[Key, Value] {
-	rnode := &leafNode[Key, Value]{
-		next: tnode.next,
-	}
+	rnode := m.newLeafNode()
+	rnode.next = tnode.next
 
 	tnode.next = rnode
 
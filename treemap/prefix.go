@@ -0,0 +1,94 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"iter"
+)
+
+// This file provides the functions for the maps whose keys are
+// strings.  They assume that the keys are ordered by the byte-wise
+// lexicographical order, which is the order that [New] uses.
+
+// prefixEnd returns the smallest string that is greater than all
+// strings that start with prefix, and true.  If there is no such
+// string, that is prefix is empty or consists of only 0xff bytes, it
+// returns false.
+func prefixEnd(prefix string) (string, bool) {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			return prefix[:i] + string([]byte{prefix[i] + 1}), true
+		}
+	}
+
+	return "", false
+}
+
+// CountPrefix returns the number of keys in m that start with prefix.
+// If prefix is empty, it returns [Map.Len].  It takes O(log n + k/b)
+// time where k is the number of the counted keys, and b is the
+// number of items in a leaf node.  m must order the keys byte-wise as
+// [New] does.  If m is created by [NewAny] with another order, such as
+// a case-insensitive or reversed one, the result is undefined.
+func CountPrefix[Value any](m *Map[string, Value], prefix string) int {
+	if prefix == "" {
+		return m.n
+	}
+
+	it := m.LowerBound(prefix)
+	if it.End() {
+		return 0
+	}
+
+	end, bounded := prefixEnd(prefix)
+	n := -it.idx
+
+	for tnode := it.node; tnode != nil; tnode = tnode.next {
+		if !bounded || m.compare(tnode.LastKey(), end) < 0 {
+			n += tnode.n
+			continue
+		}
+
+		i, _ := m.search(tnode.Keys(), end)
+
+		return n + i
+	}
+
+	return n
+}
+
+// PrefixRange returns an iterator over the items in m whose keys start
+// with prefix in the sorted order.  If prefix is empty, it yields all
+// items.  Like [CountPrefix], m must order the keys byte-wise as [New]
+// does; otherwise, the result is undefined.
+func PrefixRange[Value any](
+	m *Map[string, Value], prefix string,
+) iter.Seq2[string, Value] {
+	end, bounded := prefixEnd(prefix)
+	if !bounded {
+		return m.LowerBound(prefix).Seq()
+	}
+
+	return m.Range(prefix, end)
+}
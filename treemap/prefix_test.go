@@ -0,0 +1,117 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixEnd(t *testing.T) {
+	for _, tc := range []struct {
+		prefix string
+		end    string
+		ok     bool
+	}{
+		{prefix: ""},
+		{prefix: "\xff"},
+		{prefix: "\xff\xff"},
+		{prefix: "a", end: "b", ok: true},
+		{prefix: "ab", end: "ac", ok: true},
+		{prefix: "a\xff", end: "b", ok: true},
+		{prefix: "a\xff\xff", end: "b", ok: true},
+		{prefix: "\xfe\xff", end: "\xff", ok: true},
+	} {
+		end, ok := prefixEnd(tc.prefix)
+
+		assert.Equal(t, tc.ok, ok, "prefix=%q", tc.prefix)
+		assert.Equal(t, tc.end, end, "prefix=%q", tc.prefix)
+	}
+}
+
+func TestCountPrefix(t *testing.T) {
+	m := New[string, int]()
+
+	assert.Equal(t, 0, CountPrefix(m, ""))
+	assert.Equal(t, 0, CountPrefix(m, "a"))
+
+	for _, c := range []string{"a", "b", "\xff"} {
+		for i := range 500 {
+			m.Insert(c+fmt.Sprintf("%03d", i), i)
+		}
+	}
+
+	m.Insert("\xff", 0)
+	m.Insert("\xff\xff", 0)
+	m.Insert("a\xff", 0)
+
+	assert.Equal(t, m.Len(), CountPrefix(m, ""))
+	assert.Equal(t, 501, CountPrefix(m, "a"))
+	assert.Equal(t, 500, CountPrefix(m, "b"))
+	assert.Equal(t, 502, CountPrefix(m, "\xff"))
+	assert.Equal(t, 1, CountPrefix(m, "\xff\xff"))
+	assert.Equal(t, 100, CountPrefix(m, "a1"))
+	assert.Equal(t, 10, CountPrefix(m, "b49"))
+	assert.Equal(t, 1, CountPrefix(m, "b499"))
+	assert.Equal(t, 0, CountPrefix(m, "b5"))
+	assert.Equal(t, 0, CountPrefix(m, "c"))
+	assert.Equal(t, 1, CountPrefix(m, "a\xff"))
+}
+
+func TestPrefixRange(t *testing.T) {
+	m := New[string, int]()
+
+	assert.Empty(t, Collect(PrefixRange(m, "")))
+	assert.Empty(t, Collect(PrefixRange(m, "\xff")))
+
+	keys := []string{
+		"a", "ab", "abc", "a\xff", "a\xff\xff", "b", "\xff", "\xff\x00",
+		"\xff\xff",
+	}
+
+	for i, k := range keys {
+		m.Insert(k, i)
+	}
+
+	assert.Equal(t, keys, collectKeys(PrefixRange(m, "")))
+	assert.Equal(t, keys[:5], collectKeys(PrefixRange(m, "a")))
+	assert.Equal(t, keys[3:5], collectKeys(PrefixRange(m, "a\xff")))
+	assert.Equal(t, keys[1:3], collectKeys(PrefixRange(m, "ab")))
+	assert.Equal(t, keys[6:], collectKeys(PrefixRange(m, "\xff")))
+	assert.Equal(t, keys[8:], collectKeys(PrefixRange(m, "\xff\xff")))
+	assert.Empty(t, collectKeys(PrefixRange(m, "c")))
+
+	for k, v := range PrefixRange(m, "ab") {
+		assert.Equal(t, slices.Index(keys, k), v)
+	}
+
+	for k := range PrefixRange(m, "a") {
+		if k == "a\xff" {
+			break
+		}
+	}
+}
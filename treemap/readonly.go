@@ -0,0 +1,101 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"iter"
+)
+
+// ReadOnlyMap is a read-only view of [Map].  It exposes only the
+// methods that do not modify the underlying Map.  It shares the nodes
+// with the Map without copying them, so that the changes in the Map
+// are visible through ReadOnlyMap, and they invalidate the iterators
+// obtained from it.  To hand out an immutable copy, copy the items
+// into a new Map, and freeze it.  The zero value is not usable; use
+// [Map.Freeze] to create one.
+type ReadOnlyMap[Key, Value any] struct {
+	m *Map[Key, Value]
+}
+
+// Freeze returns the read-only view of m.
+func (m *Map[Key, Value]) Freeze() ReadOnlyMap[Key, Value] {
+	return ReadOnlyMap[Key, Value]{
+		m: m,
+	}
+}
+
+// Find returns value associated by key.  It is equivalent to
+// [Map.Find].
+func (r ReadOnlyMap[Key, Value]) Find(key Key) (Value, bool) {
+	return r.m.Find(key)
+}
+
+// LowerBound returns an iterator over the items whose keys are
+// greater than or equal to key in the sorted order.  Unlike
+// [Map.LowerBound], it does not return [Iterator] because it can
+// modify the value.
+func (r ReadOnlyMap[Key, Value]) LowerBound(key Key) iter.Seq2[Key, Value] {
+	return r.m.LowerBound(key).Seq()
+}
+
+// Len returns the number of items.
+func (r ReadOnlyMap[Key, Value]) Len() int {
+	return r.m.n
+}
+
+// All returns an iterator over all items in the sorted order.
+func (r ReadOnlyMap[Key, Value]) All() iter.Seq2[Key, Value] {
+	return r.m.Begin().Seq()
+}
+
+// Keys returns an iterator over all keys in the sorted order.
+func (r ReadOnlyMap[Key, Value]) Keys() iter.Seq[Key] {
+	return r.m.Keys()
+}
+
+// Values returns an iterator over all values in the sorted order of
+// their keys.
+func (r ReadOnlyMap[Key, Value]) Values() iter.Seq[Value] {
+	return r.m.Values()
+}
+
+// Range returns an iterator over the items whose keys are in [lo,
+// hi) in the sorted order.  It is equivalent to [Map.Range].
+func (r ReadOnlyMap[Key, Value]) Range(lo, hi Key) iter.Seq2[Key, Value] {
+	return r.m.Range(lo, hi)
+}
+
+// RangeBackward returns an iterator over the items whose keys are in
+// [lo, hi) in the reverse sorted order.  It is equivalent to
+// [Map.RangeBackward].
+func (r ReadOnlyMap[Key, Value]) RangeBackward(
+	lo, hi Key,
+) iter.Seq2[Key, Value] {
+	return r.m.RangeBackward(lo, hi)
+}
+
+// String returns the string representation of the underlying Map.
+func (r ReadOnlyMap[Key, Value]) String() string {
+	return r.m.String()
+}
@@ -0,0 +1,100 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyMap(t *testing.T) {
+	m := New[int, int]()
+	r := m.Freeze()
+
+	assert.Equal(t, 0, r.Len())
+	assert.Empty(t, Collect(r.All()))
+
+	for i := range 100 {
+		m.Insert(i, i+1)
+	}
+
+	assert.Equal(t, 100, r.Len())
+
+	v, ok := r.Find(10)
+
+	require.True(t, ok)
+	assert.Equal(t, 11, v)
+
+	_, ok = r.Find(100)
+
+	assert.False(t, ok)
+	assert.Equal(t, slices.Collect(genIntSeqStep(0, 100, 1)),
+		slices.Collect(r.Keys()))
+	assert.Equal(t, slices.Collect(genIntSeqStep(1, 101, 1)),
+		slices.Collect(r.Values()))
+	assert.Equal(t, Collect(m.Begin().Seq()), Collect(r.All()))
+	assert.Equal(t, []int{98, 99}, collectKeys(r.LowerBound(98)))
+	assert.Equal(t, []int{10, 11}, collectKeys(r.Range(10, 12)))
+	assert.Equal(t, []int{11, 10}, collectKeys(r.RangeBackward(10, 12)))
+	assert.Equal(t, m.String(), r.String())
+
+	m.Remove(10)
+
+	_, ok = r.Find(10)
+
+	assert.False(t, ok)
+	assert.Equal(t, 99, r.Len())
+}
+
+func TestReadOnlyMapConcurrentFind(t *testing.T) {
+	m := New[int, int]()
+	m.UseLeafCache()
+
+	for i := range 1000 {
+		m.Insert(i, i+1)
+	}
+
+	r := m.Freeze()
+
+	var wg sync.WaitGroup
+
+	for j := range 4 {
+		wg.Go(func() {
+			for i := range 1000 {
+				k := (i*7 + j*250) % 1000
+
+				v, ok := r.Find(k)
+
+				assert.True(t, ok)
+				assert.Equal(t, k+1, v)
+			}
+		})
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,119 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"cmp"
+	"iter"
+)
+
+// OrderedSet is the sorted set of keys.  It is backed by [Map] whose
+// values are empty.
+type OrderedSet[Key any] struct {
+	m *Map[Key, struct{}]
+}
+
+// NewOrderedSet returns new empty OrderedSet.
+func NewOrderedSet[Key cmp.Ordered]() *OrderedSet[Key] {
+	return &OrderedSet[Key]{
+		m: New[Key, struct{}](),
+	}
+}
+
+// CollectKeys returns new OrderedSet that contains the keys yielded
+// by seq.  If seq yields the keys in the strictly increasing order,
+// they are bulk-loaded into the set, which is much faster than adding
+// them one by one.  The keys that are out of order are not lost; they
+// are added one by one after the others are loaded.
+func CollectKeys[Key cmp.Ordered](seq iter.Seq[Key]) *OrderedSet[Key] {
+	s := NewOrderedSet[Key]()
+	b := newBuilder(s.m)
+
+	var (
+		rest    []Key
+		last    Key
+		hasLast bool
+	)
+
+	for key := range seq {
+		if hasLast && cmp.Compare(last, key) >= 0 {
+			rest = append(rest, key)
+			continue
+		}
+
+		b.add(key, struct{}{})
+
+		last = key
+		hasLast = true
+	}
+
+	b.finish()
+
+	for _, key := range rest {
+		s.Add(key)
+	}
+
+	return s
+}
+
+// Add adds key to s.  It returns true if key is added, or false if
+// key is already in s.
+func (s *OrderedSet[Key]) Add(key Key) bool {
+	_, existed := s.m.tryInsert(key, struct{}{})
+
+	return !existed
+}
+
+// Remove removes key from s.  It returns true if key is removed, or
+// false if key is not in s.
+func (s *OrderedSet[Key]) Remove(key Key) bool {
+	_, ok := s.m.Remove(key)
+
+	return ok
+}
+
+// Contains returns true if key is in s.
+func (s *OrderedSet[Key]) Contains(key Key) bool {
+	_, ok := s.m.Find(key)
+
+	return ok
+}
+
+// ContainsMany returns whether keys are in s.  The returned slice is
+// parallel to keys.  If keys are sorted, it sweeps s once in that
+// order.  Otherwise, it does the same with the sorted copy of the
+// indices of keys.  See [Map.GetMany] for details.
+func (s *OrderedSet[Key]) ContainsMany(keys []Key) []bool {
+	return s.m.ContainsMany(keys)
+}
+
+// Len returns the number of keys in s.
+func (s *OrderedSet[Key]) Len() int {
+	return s.m.n
+}
+
+// All returns an iterator over all keys in s in the sorted order.
+func (s *OrderedSet[Key]) All() iter.Seq[Key] {
+	return s.m.Keys()
+}
@@ -0,0 +1,94 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedSet(t *testing.T) {
+	s := NewOrderedSet[int]()
+
+	assert.Equal(t, 0, s.Len())
+	assert.False(t, s.Contains(1))
+	assert.True(t, s.Add(1))
+	assert.False(t, s.Add(1))
+	assert.True(t, s.Add(0))
+	assert.True(t, s.Contains(1))
+	assert.Equal(t, []int{0, 1}, slices.Collect(s.All()))
+	assert.True(t, s.Remove(1))
+	assert.False(t, s.Remove(1))
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestCollectKeys(t *testing.T) {
+	s := CollectKeys(slices.Values([]int{}))
+
+	assert.Equal(t, 0, s.Len())
+
+	keys := slices.Collect(genIntSeqStep(0, 3000, 3))
+	s = CollectKeys(slices.Values(keys))
+
+	assert.Equal(t, keys, slices.Collect(s.All()))
+
+	verifyMap(t, s.m, 0, 2997)
+
+	s = CollectKeys(slices.Values([]int{1, 3, 3, 2, 5, 0}))
+
+	assert.Equal(t, []int{0, 1, 2, 3, 5}, slices.Collect(s.All()))
+
+	verifyMap(t, s.m, 0, 5)
+}
+
+func TestOrderedSetContainsMany(t *testing.T) {
+	s := NewOrderedSet[int]()
+
+	assert.Equal(t, []bool{false, false}, s.ContainsMany([]int{1, 2}))
+
+	for i := range 1000 {
+		s.Add(i * 2)
+	}
+
+	keys := slices.Collect(genIntSeqStep(-1, 2002, 1))
+	rkeys := slices.Clone(keys)
+
+	slices.Reverse(rkeys)
+
+	for _, keys := range [][]int{keys, rkeys} {
+		found := s.ContainsMany(keys)
+
+		require.Len(t, found, len(keys))
+
+		for i, k := range keys {
+			assert.Equal(t, s.Contains(k), found[i], "key=%d", k)
+		}
+	}
+
+	assert.Equal(t, []bool{true, false, true},
+		s.ContainsMany([]int{10, 3, 10}))
+}
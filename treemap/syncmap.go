@@ -0,0 +1,64 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+// This file provides the methods that have the same signatures as
+// the ones of sync.Map to ease migration from it.  Unlike sync.Map,
+// Map is not safe for concurrent use by multiple goroutines without
+// additional locking.
+
+// Load returns the value stored for key and true.  If no value is
+// present, it returns zero value and false.  It is equivalent to
+// [Map.Find].
+func (m *Map[Key, Value]) Load(key Key) (Value, bool) {
+	return m.Find(key)
+}
+
+// Store sets the value for key.  It is equivalent to [Map.Insert].
+func (m *Map[Key, Value]) Store(key Key, value Value) {
+	m.Insert(key, value)
+}
+
+// Delete removes the value for key.  It is equivalent to
+// [Map.Remove].
+func (m *Map[Key, Value]) Delete(key Key) {
+	m.Remove(key)
+}
+
+// LoadOrStore returns the existing value for key and true if present.
+// Otherwise, it stores value and returns it and false.
+func (m *Map[Key, Value]) LoadOrStore(
+	key Key, value Value,
+) (Value, bool) {
+	it, loaded := m.tryInsert(key, value)
+
+	return it.Value(), loaded
+}
+
+// LoadAndDelete removes the value for key and returns the previous
+// value and true if present.  Otherwise, it returns zero value and
+// false.  It is equivalent to [Map.Remove].
+func (m *Map[Key, Value]) LoadAndDelete(key Key) (Value, bool) {
+	return m.Remove(key)
+}
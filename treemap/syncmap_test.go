@@ -0,0 +1,102 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapLoadStoreDelete(t *testing.T) {
+	m := New[string, int]()
+
+	_, ok := m.Load("foo")
+
+	assert.False(t, ok)
+
+	m.Store("foo", 1)
+	m.Store("bar", 2)
+	m.Store("foo", 3)
+
+	v, ok := m.Load("foo")
+
+	require.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	m.Delete("foo")
+	m.Delete("baz")
+
+	_, ok = m.Load("foo")
+
+	assert.False(t, ok)
+	assert.Equal(t, []string{"bar"}, slices.Collect(m.Keys()))
+}
+
+func TestMapLoadOrStore(t *testing.T) {
+	m := New[int, int]()
+
+	for i := range 1000 {
+		v, loaded := m.LoadOrStore(i, i+1)
+
+		assert.False(t, loaded)
+		assert.Equal(t, i+1, v)
+	}
+
+	for i := range 1000 {
+		v, loaded := m.LoadOrStore(i, 0)
+
+		assert.True(t, loaded)
+		assert.Equal(t, i+1, v)
+	}
+
+	verifyMap(t, m, 0, 999)
+
+	assert.Equal(t, slices.Collect(genIntSeq(1001))[1:],
+		slices.Collect(m.Values()))
+}
+
+func TestMapLoadAndDelete(t *testing.T) {
+	m := New[int, string]()
+
+	v, loaded := m.LoadAndDelete(1)
+
+	assert.False(t, loaded)
+	assert.Empty(t, v)
+
+	m.Store(1, "foo")
+	m.Store(2, "bar")
+
+	v, loaded = m.LoadAndDelete(1)
+
+	assert.True(t, loaded)
+	assert.Equal(t, "foo", v)
+
+	_, loaded = m.LoadAndDelete(1)
+
+	assert.False(t, loaded)
+	assert.Equal(t, []int{2}, slices.Collect(m.Keys()))
+}
@@ -0,0 +1,178 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// validator walks the tree of Map and checks its invariants.
+type validator[Key, Value any] struct {
+	m *Map[Key, Value]
+	// prev is the leaf node that is visited last.
+	prev *leafNode[Key, Value]
+	// n is the number of items in the visited leaf nodes.
+	n int
+}
+
+// Validate checks the structural invariants of m, and returns an
+// error that describes the first violation found.  It checks that the
+// keys are sorted, that each node has the permitted number of items,
+// that the keys in each subtree are within the range that the parent
+// node specifies, that the leaf nodes are linked in order, and that
+// the number of items equals [Map.Len].  It returns nil if m is
+// valid.  It takes O(n) time.  It is intended for testing the code
+// that builds on Map.
+func (m *Map[Key, Value]) Validate() error {
+	v := validator[Key, Value]{
+		m: m,
+	}
+
+	var lo, hi *Key
+
+	if err := v.validateNode(m.root, m.height, lo, hi); err != nil {
+		return err
+	}
+
+	if v.prev != m.back {
+		return errors.New("treemap: back is not the last leaf node")
+	}
+
+	if m.back.next != nil {
+		return errors.New("treemap: back has the next leaf node")
+	}
+
+	if v.n != m.n {
+		return fmt.Errorf("treemap: leaf nodes have %d items, but Len is %d",
+			v.n, m.n)
+	}
+
+	return nil
+}
+
+// validateNode checks the subtree rooted at node whose height is
+// height.  If lo is not nil, all keys in the subtree must be greater
+// than *lo.  If hi is not nil, all keys in the subtree must be less
+// than or equal to *hi.
+func (v *validator[Key, Value]) validateNode(
+	node node[Key, Value], height int, lo, hi *Key,
+) error {
+	m := v.m
+
+	if node.Size() > maxNodes {
+		return fmt.Errorf("treemap: node has %d items which exceeds %d",
+			node.Size(), maxNodes)
+	}
+
+	if node != m.root && node.Size() < m.minSize {
+		return fmt.Errorf("treemap: non-root node has %d items which is "+
+			"less than %d", node.Size(), m.minSize)
+	}
+
+	if height == 0 {
+		tnode, ok := node.(*leafNode[Key, Value])
+		if !ok {
+			return errors.New("treemap: node at height 0 is not a leaf node")
+		}
+
+		return v.validateLeaf(tnode, lo, hi)
+	}
+
+	inode, ok := node.(*internalNode[Key, Value])
+	if !ok {
+		return fmt.Errorf("treemap: node at height %d is not an internal node",
+			height)
+	}
+
+	if inode.n < 2 {
+		return fmt.Errorf("treemap: internal node has %d children", inode.n)
+	}
+
+	for i := range inode.n {
+		if i+1 < inode.n && hi != nil && m.compare(inode.keys[i], *hi) > 0 {
+			return fmt.Errorf("treemap: key %v in internal node is greater "+
+				"than the upper bound %v", inode.keys[i], *hi)
+		}
+
+		// The last key is not used for the search.  Its parent
+		// determines the upper bound of the last child.
+		chi := &inode.keys[i]
+		if i+1 == inode.n {
+			chi = hi
+		}
+
+		if err := v.validateNode(inode.nodes[i], height-1, lo, chi); err != nil {
+			return err
+		}
+
+		lo = &inode.keys[i]
+	}
+
+	return nil
+}
+
+// validateLeaf checks tnode.  See [validator.validateNode] for lo and
+// hi.
+func (v *validator[Key, Value]) validateLeaf(
+	tnode *leafNode[Key, Value], lo, hi *Key,
+) error {
+	m := v.m
+
+	if tnode.prev != v.prev {
+		return errors.New("treemap: leaf node has the wrong previous node")
+	}
+
+	if v.prev == nil {
+		if m.front != tnode {
+			return errors.New("treemap: front is not the first leaf node")
+		}
+	} else if v.prev.next != tnode {
+		return errors.New("treemap: leaf node has the wrong next node")
+	}
+
+	for i, key := range tnode.Keys() {
+		if i > 0 && m.compare(tnode.keys[i-1], key) >= 0 {
+			return fmt.Errorf("treemap: keys %v and %v in leaf node are not "+
+				"in the strictly increasing order", tnode.keys[i-1], key)
+		}
+	}
+
+	if tnode.n > 0 {
+		if lo != nil && m.compare(tnode.keys[0], *lo) <= 0 {
+			return fmt.Errorf("treemap: key %v is not greater than the lower "+
+				"bound %v", tnode.keys[0], *lo)
+		}
+
+		if hi != nil && m.compare(tnode.LastKey(), *hi) > 0 {
+			return fmt.Errorf("treemap: key %v is greater than the upper "+
+				"bound %v", tnode.LastKey(), *hi)
+		}
+	}
+
+	v.prev = tnode
+	v.n += tnode.n
+
+	return nil
+}
@@ -0,0 +1,140 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newValidateMap() *Map[int, int] {
+	m := New[int, int]()
+
+	for i := range 1000 {
+		m.Insert(i, i)
+	}
+
+	return m
+}
+
+func TestMapValidate(t *testing.T) {
+	m := New[int, int]()
+
+	require.NoError(t, m.Validate())
+
+	m = newValidateMap()
+
+	require.NoError(t, m.Validate())
+
+	for i := range 500 {
+		m.Remove(i * 2)
+	}
+
+	require.NoError(t, m.Validate())
+
+	m.Compact()
+
+	require.NoError(t, m.Validate())
+
+	m.Clear()
+
+	require.NoError(t, m.Validate())
+}
+
+func TestMapValidateCorrupted(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		corrupt func(m *Map[int, int])
+		err     string
+	}{
+		{
+			name: "len",
+			corrupt: func(m *Map[int, int]) {
+				m.n++
+			},
+			err: "treemap: leaf nodes have 1000 items, but Len is 1001",
+		},
+		{
+			name: "unsorted",
+			corrupt: func(m *Map[int, int]) {
+				m.front.keys[0], m.front.keys[1] = m.front.keys[1],
+					m.front.keys[0]
+			},
+			err: "treemap: keys 1 and 0 in leaf node are not in the " +
+				"strictly increasing order",
+		},
+		{
+			name: "out of bound",
+			corrupt: func(m *Map[int, int]) {
+				m.front.keys[m.front.n-1] = 5000
+			},
+			err: "treemap: key 5000 is greater than the upper bound 15",
+		},
+		{
+			name: "prev",
+			corrupt: func(m *Map[int, int]) {
+				m.back.prev = nil
+			},
+			err: "treemap: leaf node has the wrong previous node",
+		},
+		{
+			name: "front",
+			corrupt: func(m *Map[int, int]) {
+				m.front = m.back
+			},
+			err: "treemap: front is not the first leaf node",
+		},
+		{
+			name: "back",
+			corrupt: func(m *Map[int, int]) {
+				m.back = m.front
+			},
+			err: "treemap: back is not the last leaf node",
+		},
+		{
+			name: "underfull",
+			corrupt: func(m *Map[int, int]) {
+				m.front.n = 1
+			},
+			err: "treemap: non-root node has 1 items which is less than 16",
+		},
+		{
+			name: "height",
+			corrupt: func(m *Map[int, int]) {
+				m.height++
+			},
+			err: "treemap: node at height 1 is not an internal node",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newValidateMap()
+
+			tc.corrupt(m)
+
+			assert.EqualError(t, m.Validate(), tc.err)
+		})
+	}
+}
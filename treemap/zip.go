@@ -0,0 +1,123 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"cmp"
+	"iter"
+)
+
+// ZipEntry is the item that [Zip] yields.  InA and InB tell whether
+// Key is in the first and the second map respectively.  A and B are
+// the values associated by Key in those maps, or zero values if Key
+// is not in them.
+type ZipEntry[Key, V1, V2 any] struct {
+	Key Key
+	A   V1
+	B   V2
+	InA bool
+	InB bool
+}
+
+// Zip returns an iterator that walks a and b together, and yields
+// each key in either of them once in the sorted order.  It takes
+// O(n+m) time where n and m are the number of items in a and b.  a
+// and b must be ordered by [cmp.Compare], which is the order that
+// [New] uses.
+func Zip[Key cmp.Ordered, V1, V2 any](
+	a *Map[Key, V1], b *Map[Key, V2],
+) iter.Seq[ZipEntry[Key, V1, V2]] {
+	return func(yield func(ZipEntry[Key, V1, V2]) bool) {
+		ita := a.Begin()
+		itb := b.Begin()
+
+		for {
+			var e ZipEntry[Key, V1, V2]
+
+			switch {
+			case ita.End() && itb.End():
+				return
+			case itb.End():
+				e.InA = true
+			case ita.End():
+				e.InB = true
+			default:
+				c := cmp.Compare(ita.Key(), itb.Key())
+				e.InA = c <= 0
+				e.InB = c >= 0
+			}
+
+			if e.InA {
+				e.Key, e.A = ita.KeyValue()
+				ita = ita.Next()
+			}
+
+			if e.InB {
+				e.Key, e.B = itb.KeyValue()
+				itb = itb.Next()
+			}
+
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// Diff compares the maps before and after, and returns the three maps:
+// added contains the items whose keys are only in after, removed
+// contains the items whose keys are only in before, and changed
+// contains the items in after whose keys are in both maps, but whose
+// values differ.  equal reports whether the two values are equal.
+// before and after must be ordered by [cmp.Compare] as [Zip]
+// requires.  The returned maps are created by [New].  It takes O(n+m)
+// time.
+func Diff[Key cmp.Ordered, Value any](
+	before, after *Map[Key, Value], equal func(a, b Value) bool,
+) (added, removed, changed *Map[Key, Value]) {
+	added = New[Key, Value]()
+	removed = New[Key, Value]()
+	changed = New[Key, Value]()
+
+	ab := newBuilder(added)
+	rb := newBuilder(removed)
+	cb := newBuilder(changed)
+
+	for e := range Zip(before, after) {
+		switch {
+		case !e.InA:
+			ab.add(e.Key, e.B)
+		case !e.InB:
+			rb.add(e.Key, e.A)
+		case !equal(e.A, e.B):
+			cb.add(e.Key, e.B)
+		}
+	}
+
+	ab.finish()
+	rb.finish()
+	cb.finish()
+
+	return added, removed, changed
+}
@@ -0,0 +1,127 @@
+// treemap-go
+//
+// Copyright (c) 2026 treemap-go contributors
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE
+// LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION
+// OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION
+// WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package treemap
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZip(t *testing.T) {
+	a := New[int, int]()
+	b := New[int, string]()
+
+	assert.Empty(t, slices.Collect(Zip(a, b)))
+
+	for i := range 100 {
+		a.Insert(i*2, i)
+	}
+
+	for i := range 100 {
+		b.Insert(i*3, "b")
+	}
+
+	var keys []int
+
+	for e := range Zip(a, b) {
+		assert.Equal(t, e.Key%2 == 0 && e.Key < 200, e.InA)
+		assert.Equal(t, e.Key%3 == 0 && e.Key < 300, e.InB)
+
+		if e.InA {
+			assert.Equal(t, e.Key/2, e.A)
+		} else {
+			assert.Equal(t, 0, e.A)
+		}
+
+		if e.InB {
+			assert.Equal(t, "b", e.B)
+		} else {
+			assert.Empty(t, e.B)
+		}
+
+		keys = append(keys, e.Key)
+	}
+
+	assert.True(t, slices.IsSorted(keys))
+	assert.Len(t, keys, 100+100-34)
+
+	assert.Equal(t, []ZipEntry[int, int, string]{
+		{Key: 0, A: 0, B: "b", InA: true, InB: true},
+		{Key: 2, A: 1, InA: true},
+	}, slices.Collect(func(yield func(ZipEntry[int, int, string]) bool) {
+		for e := range Zip(a, b) {
+			if e.Key == 3 || !yield(e) {
+				return
+			}
+		}
+	}))
+	assert.Len(t, slices.Collect(Zip(a, New[int, string]())), 100)
+	assert.Len(t, slices.Collect(Zip(New[int, int](), b)), 100)
+}
+
+func TestDiff(t *testing.T) {
+	before := New[int, int]()
+	after := New[int, int]()
+
+	added, removed, changed := Diff(before, after, func(a, b int) bool {
+		return a == b
+	})
+
+	assert.Equal(t, 0, added.Len())
+	assert.Equal(t, 0, removed.Len())
+	assert.Equal(t, 0, changed.Len())
+
+	for i := range 1000 {
+		before.Insert(i, i)
+	}
+
+	for i := 500; i < 1500; i++ {
+		after.Insert(i, i%10)
+	}
+
+	added, removed, changed = Diff(before, after, func(a, b int) bool {
+		return a == b
+	})
+
+	assert.Equal(t, slices.Collect(genIntSeqStep(1000, 1500, 1)),
+		slices.Collect(added.Keys()))
+	assert.Equal(t, slices.Collect(genIntSeqStep(0, 500, 1)),
+		slices.Collect(removed.Keys()))
+	assert.Equal(t, 500, changed.Len())
+
+	for k, v := range changed.Begin().Seq() {
+		assert.NotEqual(t, k, v)
+		assert.Equal(t, k%10, v)
+	}
+
+	for k, v := range removed.Begin().Seq() {
+		assert.Equal(t, k, v)
+	}
+
+	verifyMap(t, added, 1000, 1499)
+	verifyMap(t, removed, 0, 499)
+	verifyMap(t, changed, 500, 999)
+}